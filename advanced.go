@@ -8,7 +8,19 @@ import (
 // === Raw Transaction Functions ===
 
 // CreateRawTransaction creates a new raw transaction spending the given inputs and creating new outputs.
-func (c *Client) CreateRawTransaction(inputs []CreateRawTransactionInput, outputs map[string]interface{}, locktime int64, replaceable bool) (string, error) {
+// Outputs are created in the order given, including OP_RETURN data outputs.
+func (c *Client) CreateRawTransaction(inputs []CreateRawTransactionInput, outputs CreateRawTransactionOutputs, locktime int64, replaceable bool) (string, error) {
+	return c.createRawTransaction(inputs, outputs, locktime, replaceable)
+}
+
+// CreateRawTransactionMap is CreateRawTransaction with outputs given as a map of address to amount.
+// Outputs are created in key order, as the map is encoded with sorted keys; it is kept for callers of the former map signature.
+func (c *Client) CreateRawTransactionMap(inputs []CreateRawTransactionInput, outputs map[string]interface{}, locktime int64, replaceable bool) (string, error) {
+	return c.createRawTransaction(inputs, outputs, locktime, replaceable)
+}
+
+// createRawTransaction calls createrawtransaction with outputs in either accepted form.
+func (c *Client) createRawTransaction(inputs []CreateRawTransactionInput, outputs interface{}, locktime int64, replaceable bool) (string, error) {
 	params := []interface{}{inputs, outputs}
 
 	if locktime != 0 {
@@ -92,6 +104,200 @@ func (c *Client) SendRawTransaction(hexstring string, maxfeerate float64) (strin
 	return result, nil
 }
 
+// TestMempoolAccept returns whether raw transactions would be accepted by the mempool, without broadcasting them.
+// More than one transaction is treated as a package; maxfeerate of 0 uses the node default.
+func (c *Client) TestMempoolAccept(rawtxs []string, maxfeerate float64) ([]TestMempoolAcceptResult, error) {
+	params := []interface{}{rawtxs}
+
+	if maxfeerate > 0 {
+		params = append(params, maxfeerate)
+	}
+
+	resp, err := c.call("testmempoolaccept", params)
+	if err != nil {
+		return nil, fmt.Errorf("testmempoolaccept RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("testmempoolaccept RPC error: %s", resp.Error.Message)
+	}
+
+	var result []TestMempoolAcceptResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal testmempoolaccept result: %w", err)
+	}
+
+	return result, nil
+}
+
+// SubmitPackage submits a package of raw transactions (a child and its unconfirmed parents, in topological order) to the mempool.
+// maxfeerate and maxburnamount of 0 use the node defaults.
+func (c *Client) SubmitPackage(rawtxs []string, maxfeerate, maxburnamount float64) (*SubmitPackageResponse, error) {
+	params := []interface{}{rawtxs}
+
+	if maxfeerate > 0 || maxburnamount > 0 {
+		// 0 would disable the fee rate check, so fill in the node default of 0.10 BTC/kvB
+		if maxfeerate == 0 {
+			maxfeerate = 0.10
+		}
+		params = append(params, maxfeerate)
+	}
+
+	if maxburnamount > 0 {
+		params = append(params, maxburnamount)
+	}
+
+	resp, err := c.call("submitpackage", params)
+	if err != nil {
+		return nil, fmt.Errorf("submitpackage RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("submitpackage RPC error: %s", resp.Error.Message)
+	}
+
+	var result SubmitPackageResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal submitpackage result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DecodeRawTransaction returns a JSON object representing the serialized, hex-encoded transaction.
+// iswitness may be nil to let the node guess the serialization format.
+func (c *Client) DecodeRawTransaction(hexstring string, iswitness *bool) (*GetRawTransactionResponse, error) {
+	params := []interface{}{hexstring}
+
+	if iswitness != nil {
+		params = append(params, *iswitness)
+	}
+
+	resp, err := c.call("decoderawtransaction", params)
+	if err != nil {
+		return nil, fmt.Errorf("decoderawtransaction RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("decoderawtransaction RPC error: %s", resp.Error.Message)
+	}
+
+	var result GetRawTransactionResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decoderawtransaction result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DecodeScript decodes a hex-encoded script.
+func (c *Client) DecodeScript(hexstring string) (*DecodeScriptResponse, error) {
+	params := []interface{}{hexstring}
+
+	resp, err := c.call("decodescript", params)
+	if err != nil {
+		return nil, fmt.Errorf("decodescript RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("decodescript RPC error: %s", resp.Error.Message)
+	}
+
+	var result DecodeScriptResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decodescript result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// FundRawTransaction adds inputs from the wallet to a raw transaction until it covers its outputs, adding change if needed.
+// options may be nil to use the wallet defaults; iswitness may be nil to let the node guess the serialization format.
+func (c *Client) FundRawTransaction(walletName, hexstring string, options *FundOptions, iswitness *bool) (*FundRawTransactionResponse, error) {
+	params := []interface{}{hexstring}
+
+	if options != nil || iswitness != nil {
+		if options != nil {
+			params = append(params, options)
+		} else {
+			params = append(params, nil)
+		}
+	}
+
+	if iswitness != nil {
+		params = append(params, *iswitness)
+	}
+
+	resp, err := c.callWithWallet("fundrawtransaction", params, walletName)
+	if err != nil {
+		return nil, fmt.Errorf("fundrawtransaction RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("fundrawtransaction RPC error: %s", resp.Error.Message)
+	}
+
+	var result FundRawTransactionResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fundrawtransaction result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// SignRawTransactionWithKey signs inputs for a raw transaction with the given base58-encoded private keys.
+// prevtxs describes previous outputs the node does not know about, such as unconfirmed or P2SH outputs.
+func (c *Client) SignRawTransactionWithKey(hexstring string, privkeys []string, prevtxs []RawTransactionPrevTx, sighashtype string) (*SignRawTransactionResponse, error) {
+	params := []interface{}{hexstring, privkeys}
+
+	if len(prevtxs) > 0 {
+		params = append(params, prevtxs)
+	} else {
+		params = append(params, nil)
+	}
+
+	if sighashtype != "" {
+		params = append(params, sighashtype)
+	}
+
+	resp, err := c.call("signrawtransactionwithkey", params)
+	if err != nil {
+		return nil, fmt.Errorf("signrawtransactionwithkey RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("signrawtransactionwithkey RPC error: %s", resp.Error.Message)
+	}
+
+	var result SignRawTransactionResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal signrawtransactionwithkey result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// CombineRawTransaction combines multiple partially signed versions of the same transaction into one.
+func (c *Client) CombineRawTransaction(txs []string) (string, error) {
+	params := []interface{}{txs}
+
+	resp, err := c.call("combinerawtransaction", params)
+	if err != nil {
+		return "", fmt.Errorf("combinerawtransaction RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return "", fmt.Errorf("combinerawtransaction RPC error: %s", resp.Error.Message)
+	}
+
+	var result string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal combinerawtransaction result: %w", err)
+	}
+
+	return result, nil
+}
+
 // === Private Key Management Functions ===
 
 // DumpPrivKey reveals the private key corresponding to address.
@@ -139,6 +345,101 @@ func (c *Client) ImportPrivKey(walletName, privkey, label string, rescan bool) e
 	return nil
 }
 
+// === Descriptor Functions ===
+
+// ImportDescriptors imports descriptors into a descriptor wallet.
+func (c *Client) ImportDescriptors(walletName string, requests []ImportDescriptorRequest) ([]ImportDescriptorResult, error) {
+	params := []interface{}{requests}
+
+	resp, err := c.callWithWallet("importdescriptors", params, walletName)
+	if err != nil {
+		return nil, fmt.Errorf("importdescriptors RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("importdescriptors RPC error: %s", resp.Error.Message)
+	}
+
+	var result []ImportDescriptorResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal importdescriptors result: %w", err)
+	}
+
+	return result, nil
+}
+
+// ListDescriptors lists the descriptors imported into a descriptor wallet, including private keys if private is true.
+func (c *Client) ListDescriptors(walletName string, private bool) (*ListDescriptorsResponse, error) {
+	params := []interface{}{}
+
+	if private {
+		params = append(params, private)
+	}
+
+	resp, err := c.callWithWallet("listdescriptors", params, walletName)
+	if err != nil {
+		return nil, fmt.Errorf("listdescriptors RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("listdescriptors RPC error: %s", resp.Error.Message)
+	}
+
+	var result ListDescriptorsResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal listdescriptors result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetDescriptorInfo analyses a descriptor and returns its canonical form and checksum.
+func (c *Client) GetDescriptorInfo(descriptor string) (*GetDescriptorInfoResponse, error) {
+	params := []interface{}{descriptor}
+
+	resp, err := c.call("getdescriptorinfo", params)
+	if err != nil {
+		return nil, fmt.Errorf("getdescriptorinfo RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("getdescriptorinfo RPC error: %s", resp.Error.Message)
+	}
+
+	var result GetDescriptorInfoResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal getdescriptorinfo result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DeriveAddresses derives one or more addresses corresponding to an output descriptor.
+// The descriptor must include its checksum; rng is required for ranged descriptors.
+func (c *Client) DeriveAddresses(descriptor string, rng *DescriptorRange) ([]string, error) {
+	params := []interface{}{descriptor}
+
+	if rng != nil {
+		params = append(params, rng)
+	}
+
+	resp, err := c.call("deriveaddresses", params)
+	if err != nil {
+		return nil, fmt.Errorf("deriveaddresses RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("deriveaddresses RPC error: %s", resp.Error.Message)
+	}
+
+	var result []string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal deriveaddresses result: %w", err)
+	}
+
+	return result, nil
+}
+
 // === Multisignature Functions ===
 
 // CreateMultisig creates a multi-signature address with n signature of m keys required.
@@ -216,6 +517,14 @@ func (c *Client) GetRawMempool(verbose bool, mempoolSequence bool) (interface{},
 		return nil, fmt.Errorf("getrawmempool RPC error: %s", resp.Error.Message)
 	}
 
+	if mempoolSequence && !verbose {
+		var result GetRawMempoolSequenceResponse
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal getrawmempool result: %w", err)
+		}
+		return result, nil
+	}
+
 	if verbose {
 		var result GetRawMempoolVerboseResponse
 		if err := json.Unmarshal(resp.Result, &result); err != nil {
@@ -245,6 +554,20 @@ func (c *Client) GetRawMempoolSimple() ([]string, error) {
 	return nil, fmt.Errorf("unexpected response type from getrawmempool")
 }
 
+// GetRawMempoolWithSequence returns the mempool transaction ids together with the mempool sequence they correspond to.
+func (c *Client) GetRawMempoolWithSequence() (*GetRawMempoolSequenceResponse, error) {
+	result, err := c.GetRawMempool(false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if sequenceResult, ok := result.(GetRawMempoolSequenceResponse); ok {
+		return &sequenceResult, nil
+	}
+
+	return nil, fmt.Errorf("unexpected response type from getrawmempool with mempool_sequence")
+}
+
 // GetRawMempoolVerbose returns detailed information about all transactions in memory pool.
 func (c *Client) GetRawMempoolVerbose() (map[string]GetRawMempoolEntry, error) {
 	result, err := c.GetRawMempool(true, false)
@@ -258,3 +581,76 @@ func (c *Client) GetRawMempoolVerbose() (map[string]GetRawMempoolEntry, error) {
 
 	return nil, fmt.Errorf("unexpected response type from verbose getrawmempool")
 }
+
+// GetMempoolEntry returns the mempool data of a single transaction.
+func (c *Client) GetMempoolEntry(txid string) (*GetRawMempoolEntry, error) {
+	resp, err := c.call("getmempoolentry", []interface{}{txid})
+	if err != nil {
+		return nil, fmt.Errorf("getmempoolentry RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("getmempoolentry RPC error: %s", resp.Error.Message)
+	}
+
+	var result GetRawMempoolEntry
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal getmempoolentry result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetMempoolAncestors returns the txids of all in-mempool ancestors of a transaction.
+func (c *Client) GetMempoolAncestors(txid string) ([]string, error) {
+	var result []string
+	if err := c.mempoolRelatives("getmempoolancestors", txid, false, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetMempoolAncestorsVerbose returns the mempool data of all in-mempool ancestors of a transaction, keyed by txid.
+func (c *Client) GetMempoolAncestorsVerbose(txid string) (map[string]GetRawMempoolEntry, error) {
+	var result map[string]GetRawMempoolEntry
+	if err := c.mempoolRelatives("getmempoolancestors", txid, true, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetMempoolDescendants returns the txids of all in-mempool descendants of a transaction.
+func (c *Client) GetMempoolDescendants(txid string) ([]string, error) {
+	var result []string
+	if err := c.mempoolRelatives("getmempooldescendants", txid, false, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetMempoolDescendantsVerbose returns the mempool data of all in-mempool descendants of a transaction, keyed by txid.
+func (c *Client) GetMempoolDescendantsVerbose(txid string) (map[string]GetRawMempoolEntry, error) {
+	var result map[string]GetRawMempoolEntry
+	if err := c.mempoolRelatives("getmempooldescendants", txid, true, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mempoolRelatives calls getmempoolancestors or getmempooldescendants and decodes the result into result.
+func (c *Client) mempoolRelatives(method string, txid string, verbose bool, result interface{}) error {
+	resp, err := c.call(method, []interface{}{txid, verbose})
+	if err != nil {
+		return fmt.Errorf("%s RPC call failed: %w", method, err)
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("%s RPC error: %s", method, resp.Error.Message)
+	}
+
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed to unmarshal %s result: %w", method, err)
+	}
+
+	return nil
+}
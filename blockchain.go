@@ -1,8 +1,11 @@
 package btcrpc
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // GetBlockchainInfo calls the getblockchaininfo RPC method
@@ -70,8 +73,18 @@ func (c *Client) GenerateToAddress(nblocks int, address string, maxtries *int) (
 
 // GetBlock calls the getblock RPC method
 // blockhash: hash of the block to retrieve
-// verbosity: 0=raw hex, 1=json object, 2=json object with transaction data
+// verbosity: 1=json object; use GetBlockRaw for 0, GetBlockVerbose for 2 and GetBlockWithPrevouts for 3
 func (c *Client) GetBlock(blockhash string, verbosity int) (*GetBlockResponse, error) {
+	// Other verbosities do not fit this struct
+	switch verbosity {
+	case 0:
+		return nil, fmt.Errorf("verbosity 0 (raw hex) not supported, use GetBlockRaw")
+	case 2:
+		return nil, fmt.Errorf("verbosity 2 not supported, use GetBlockVerbose")
+	case 3:
+		return nil, fmt.Errorf("verbosity 3 not supported, use GetBlockWithPrevouts")
+	}
+
 	// Prepare parameters
 	params := []interface{}{blockhash}
 
@@ -86,13 +99,71 @@ func (c *Client) GetBlock(blockhash string, verbosity int) (*GetBlockResponse, e
 		return nil, fmt.Errorf("failed to call getblock: %v", err)
 	}
 
-	// For verbosity 0, return raw hex (not implemented in this struct)
-	if verbosity == 0 {
-		return nil, fmt.Errorf("verbosity 0 (raw hex) not supported, use verbosity 1 or 2")
+	// Parse the result
+	var block GetBlockResponse
+	if err := json.Unmarshal(resp.Result, &block); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block info: %v", err)
+	}
+
+	return &block, nil
+}
+
+// GetBlockRaw calls the getblock RPC method with verbosity 0
+// blockhash: hash of the block to retrieve
+// Returns the serialized block
+func (c *Client) GetBlockRaw(blockhash string) ([]byte, error) {
+	// Prepare parameters
+	params := []interface{}{blockhash, 0}
+
+	// Call the RPC method
+	resp, err := c.call("getblock", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getblock: %v", err)
 	}
 
 	// Parse the result
-	var block GetBlockResponse
+	var blockHex string
+	if err := json.Unmarshal(resp.Result, &blockHex); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block hex: %v", err)
+	}
+
+	block, err := hex.DecodeString(blockHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode block hex: %v", err)
+	}
+
+	return block, nil
+}
+
+// GetBlockVerbose calls the getblock RPC method with verbosity 2
+// blockhash: hash of the block to retrieve
+// The result includes every transaction decoded, with its fee when the undo data is available
+func (c *Client) GetBlockVerbose(blockhash string) (*GetBlockVerboseResponse, error) {
+	return c.getBlockVerbose(blockhash, 2)
+}
+
+// GetBlockWithPrevouts calls the getblock RPC method with verbosity 3
+// blockhash: hash of the block to retrieve
+// Like GetBlockVerbose, but every non-coinbase input also carries the output it spends
+// (value, script and height), so fees and input values need no extra calls.
+// The undo data is not available for pruned blocks, in which case prevouts are missing.
+func (c *Client) GetBlockWithPrevouts(blockhash string) (*GetBlockVerboseResponse, error) {
+	return c.getBlockVerbose(blockhash, 3)
+}
+
+// getBlockVerbose calls the getblock RPC method with verbosity 2 or 3
+func (c *Client) getBlockVerbose(blockhash string, verbosity int) (*GetBlockVerboseResponse, error) {
+	// Prepare parameters
+	params := []interface{}{blockhash, verbosity}
+
+	// Call the RPC method
+	resp, err := c.call("getblock", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getblock: %v", err)
+	}
+
+	// Parse the result
+	var block GetBlockVerboseResponse
 	if err := json.Unmarshal(resp.Result, &block); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal block info: %v", err)
 	}
@@ -121,6 +192,536 @@ func (c *Client) GetBlockHash(height int) (string, error) {
 	return string(blockHash), nil
 }
 
+// GetBlockHeader calls the getblockheader RPC method
+// blockhash: hash of the block whose header to retrieve
+func (c *Client) GetBlockHeader(blockhash string) (*GetBlockHeaderResponse, error) {
+	// Prepare parameters
+	params := []interface{}{blockhash, true}
+
+	// Call the RPC method
+	resp, err := c.call("getblockheader", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getblockheader: %v", err)
+	}
+
+	// Parse the result
+	var header GetBlockHeaderResponse
+	if err := json.Unmarshal(resp.Result, &header); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block header: %v", err)
+	}
+
+	return &header, nil
+}
+
+// GetBlockHeaderRaw calls the getblockheader RPC method with verbose=false
+// blockhash: hash of the block whose header to retrieve
+// Returns the 80-byte serialized header
+func (c *Client) GetBlockHeaderRaw(blockhash string) ([]byte, error) {
+	// Prepare parameters
+	params := []interface{}{blockhash, false}
+
+	// Call the RPC method
+	resp, err := c.call("getblockheader", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getblockheader: %v", err)
+	}
+
+	// Parse the result
+	var headerHex string
+	if err := json.Unmarshal(resp.Result, &headerHex); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block header hex: %v", err)
+	}
+
+	header, err := hex.DecodeString(headerHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode block header hex: %v", err)
+	}
+	if len(header) != 80 {
+		return nil, fmt.Errorf("unexpected block header length %d", len(header))
+	}
+
+	return header, nil
+}
+
+// GetBestBlockHash calls the getbestblockhash RPC method
+func (c *Client) GetBestBlockHash() (string, error) {
+	// Call the RPC method
+	resp, err := c.call("getbestblockhash", []interface{}{})
+	if err != nil {
+		return "", fmt.Errorf("failed to call getbestblockhash: %v", err)
+	}
+
+	// Parse the result
+	var blockHash string
+	if err := json.Unmarshal(resp.Result, &blockHash); err != nil {
+		return "", fmt.Errorf("failed to unmarshal best block hash: %v", err)
+	}
+
+	return blockHash, nil
+}
+
+// GetBlockCount calls the getblockcount RPC method
+// Returns the height of the most-work fully-validated chain
+func (c *Client) GetBlockCount() (int, error) {
+	// Call the RPC method
+	resp, err := c.call("getblockcount", []interface{}{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to call getblockcount: %v", err)
+	}
+
+	// Parse the result
+	var count int
+	if err := json.Unmarshal(resp.Result, &count); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal block count: %v", err)
+	}
+
+	return count, nil
+}
+
+// GetDifficulty calls the getdifficulty RPC method
+// Returns the proof-of-work difficulty as a multiple of the minimum difficulty
+func (c *Client) GetDifficulty() (float64, error) {
+	// Call the RPC method
+	resp, err := c.call("getdifficulty", []interface{}{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to call getdifficulty: %v", err)
+	}
+
+	// Parse the result
+	var difficulty float64
+	if err := json.Unmarshal(resp.Result, &difficulty); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal difficulty: %v", err)
+	}
+
+	return difficulty, nil
+}
+
+// GetChainTips calls the getchaintips RPC method
+// Returns every known tip in the block tree, including the main chain and orphaned branches
+func (c *Client) GetChainTips() ([]ChainTip, error) {
+	// Call the RPC method
+	resp, err := c.call("getchaintips", []interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getchaintips: %v", err)
+	}
+
+	// Parse the result
+	var tips []ChainTip
+	if err := json.Unmarshal(resp.Result, &tips); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chain tips: %v", err)
+	}
+
+	return tips, nil
+}
+
+// GetBlockStats calls the getblockstats RPC method for the block at height
+// height: height of the block to compute statistics for
+// stats: names of the statistics to compute, such as "avgfee", "feerate_percentiles",
+// "total_weight" or "utxo_increase"; empty computes all of them
+// Fee statistics need the block's undo data, so they fail for pruned blocks.
+func (c *Client) GetBlockStats(height int, stats []string) (*GetBlockStatsResponse, error) {
+	return c.getBlockStats(height, stats)
+}
+
+// GetBlockStatsByHash calls the getblockstats RPC method for the block with the given hash
+// blockhash: hash of the block to compute statistics for
+// stats: names of the statistics to compute, empty computes all of them
+func (c *Client) GetBlockStatsByHash(blockhash string, stats []string) (*GetBlockStatsResponse, error) {
+	return c.getBlockStats(blockhash, stats)
+}
+
+// getBlockStats calls the getblockstats RPC method with a height or a hash
+func (c *Client) getBlockStats(hashOrHeight interface{}, stats []string) (*GetBlockStatsResponse, error) {
+	// Prepare parameters
+	params := []interface{}{hashOrHeight}
+	if len(stats) > 0 {
+		params = append(params, stats)
+	}
+
+	// Call the RPC method
+	resp, err := c.call("getblockstats", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getblockstats: %v", err)
+	}
+
+	// Parse the result
+	var result GetBlockStatsResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block stats: %v", err)
+	}
+
+	return &result, nil
+}
+
+// GetChainTxStats calls the getchaintxstats RPC method
+// nblocks: size of the window in blocks, 0 for the default of one month
+// blockhash: hash of the block that ends the window, empty for the best block
+func (c *Client) GetChainTxStats(nblocks int, blockhash string) (*GetChainTxStatsResponse, error) {
+	// Prepare parameters
+	params := []interface{}{}
+	if nblocks > 0 || blockhash != "" {
+		var window interface{}
+		if nblocks > 0 {
+			window = nblocks
+		}
+		params = append(params, window)
+	}
+	if blockhash != "" {
+		params = append(params, blockhash)
+	}
+
+	// Call the RPC method
+	resp, err := c.call("getchaintxstats", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getchaintxstats: %v", err)
+	}
+
+	// Parse the result
+	var result GetChainTxStatsResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chain tx stats: %v", err)
+	}
+
+	return &result, nil
+}
+
+// GetTxOut calls the gettxout RPC method
+// txid: transaction ID of the output
+// n: output index
+// includeMempool: whether outputs created and spent in the mempool are taken into account
+// Returns nil without an error when the output is spent or does not exist
+func (c *Client) GetTxOut(txid string, n int, includeMempool bool) (*GetTxOutResponse, error) {
+	// Prepare parameters
+	params := []interface{}{txid, n, includeMempool}
+
+	// Call the RPC method
+	resp, err := c.call("gettxout", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call gettxout: %v", err)
+	}
+
+	// Parse the result
+	if len(resp.Result) == 0 || string(resp.Result) == "null" {
+		return nil, nil
+	}
+	var result GetTxOutResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tx out: %v", err)
+	}
+
+	return &result, nil
+}
+
+// GetTxOutSetInfo calls the gettxoutsetinfo RPC method
+// Without coinstatsindex this walks the whole UTXO set and can take minutes, so it is bounded by ctx only
+// hashType: TxOutSetHashSerialized3, TxOutSetHashMuHash or TxOutSetHashNone; empty for the node default
+// hashOrHeight: nil for the tip, or a block height (int) or block hash (string); requires coinstatsindex
+// useIndex: whether to use coinstatsindex, nil for the node default
+func (c *Client) GetTxOutSetInfo(ctx context.Context, hashType string, hashOrHeight interface{}, useIndex *bool) (*GetTxOutSetInfoResponse, error) {
+	// Prepare parameters, with null standing in for skipped arguments
+	params := []interface{}{}
+	if hashType != "" || hashOrHeight != nil || useIndex != nil {
+		var ht interface{}
+		if hashType != "" {
+			ht = hashType
+		}
+		params = append(params, ht)
+	}
+	if hashOrHeight != nil || useIndex != nil {
+		params = append(params, hashOrHeight)
+	}
+	if useIndex != nil {
+		params = append(params, *useIndex)
+	}
+
+	// Call the RPC method
+	resp, err := c.callContext(ctx, "gettxoutsetinfo", params, "", noTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call gettxoutsetinfo: %w", err)
+	}
+
+	// Parse the result
+	var result GetTxOutSetInfoResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tx out set info: %v", err)
+	}
+
+	return &result, nil
+}
+
+// ScanTxOutSet calls the scantxoutset RPC method with the start action
+// Scans the UTXO set for outputs matching the scan objects without involving a wallet.
+// The node runs one scan at a time and a scan can take minutes, so the call is bounded by
+// ctx only. If ctx is done before the scan finishes, the scan is aborted on the node.
+// objects: descriptors to scan for, see ScanAddress and ScanDescriptor
+func (c *Client) ScanTxOutSet(ctx context.Context, objects []ScanObject) (*ScanTxOutSetResponse, error) {
+	// Prepare parameters
+	params := []interface{}{"start", objects}
+
+	// Abort the node-side scan if the caller gives up, it would otherwise run to completion
+	stop := context.AfterFunc(ctx, func() {
+		c.ScanTxOutSetAbort()
+	})
+	defer stop()
+
+	// Call the RPC method
+	resp, err := c.callContext(ctx, "scantxoutset", params, "", noTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call scantxoutset: %w", err)
+	}
+
+	// Parse the result
+	var result ScanTxOutSetResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scan result: %v", err)
+	}
+	if !result.Success {
+		return &result, fmt.Errorf("scantxoutset was aborted")
+	}
+
+	return &result, nil
+}
+
+// ScanTxOutSetWithProgress runs ScanTxOutSet and calls progress with the completion percentage every interval
+func (c *Client) ScanTxOutSetWithProgress(ctx context.Context, objects []ScanObject, interval time.Duration, progress func(float64)) (*ScanTxOutSetResponse, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid progress interval %v", interval)
+	}
+
+	type scanResult struct {
+		result *ScanTxOutSetResponse
+		err    error
+	}
+	done := make(chan scanResult, 1)
+	go func() {
+		result, err := c.ScanTxOutSet(ctx, objects)
+		done <- scanResult{result, err}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case r := <-done:
+			if r.err == nil {
+				progress(100)
+			}
+			return r.result, r.err
+		case <-ticker.C:
+			if percent, running, err := c.ScanTxOutSetStatus(); err == nil && running {
+				progress(percent)
+			}
+		}
+	}
+}
+
+// ScanTxOutSetStatus calls the scantxoutset RPC method with the status action
+// Returns the completion percentage of the running scan, and false if no scan is running
+func (c *Client) ScanTxOutSetStatus() (float64, bool, error) {
+	// Call the RPC method
+	resp, err := c.call("scantxoutset", []interface{}{"status"})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to call scantxoutset: %v", err)
+	}
+
+	// Parse the result
+	if len(resp.Result) == 0 || string(resp.Result) == "null" {
+		return 0, false, nil
+	}
+	var status struct {
+		Progress float64 `json:"progress"`
+	}
+	if err := json.Unmarshal(resp.Result, &status); err != nil {
+		return 0, false, fmt.Errorf("failed to unmarshal scan status: %v", err)
+	}
+
+	return status.Progress, true, nil
+}
+
+// ScanTxOutSetAbort calls the scantxoutset RPC method with the abort action
+// Returns true if a running scan was aborted
+func (c *Client) ScanTxOutSetAbort() (bool, error) {
+	// Call the RPC method
+	resp, err := c.call("scantxoutset", []interface{}{"abort"})
+	if err != nil {
+		return false, fmt.Errorf("failed to call scantxoutset: %v", err)
+	}
+
+	// Parse the result
+	var aborted bool
+	if err := json.Unmarshal(resp.Result, &aborted); err != nil {
+		return false, fmt.Errorf("failed to unmarshal scan abort result: %v", err)
+	}
+
+	return aborted, nil
+}
+
+// GetTxOutProof calls the gettxoutproof RPC method
+// txids: transactions to prove, all in the same block
+// blockhash: block to look in, empty to let the node find it (needs -txindex unless an output is unspent)
+// Returns the serialized merkle block as hex, which the merkle package can verify offline
+func (c *Client) GetTxOutProof(txids []string, blockhash string) (string, error) {
+	// Prepare parameters
+	params := []interface{}{txids}
+	if blockhash != "" {
+		params = append(params, blockhash)
+	}
+
+	// Call the RPC method
+	resp, err := c.call("gettxoutproof", params)
+	if err != nil {
+		return "", fmt.Errorf("failed to call gettxoutproof: %v", err)
+	}
+
+	// Parse the result
+	var proof string
+	if err := json.Unmarshal(resp.Result, &proof); err != nil {
+		return "", fmt.Errorf("failed to unmarshal tx out proof: %v", err)
+	}
+
+	return proof, nil
+}
+
+// VerifyTxOutProof calls the verifytxoutproof RPC method
+// proof: hex-encoded proof returned by gettxoutproof
+// Returns the txids the proof commits to, or an error if its block is not in the best chain
+func (c *Client) VerifyTxOutProof(proof string) ([]string, error) {
+	// Prepare parameters
+	params := []interface{}{proof}
+
+	// Call the RPC method
+	resp, err := c.call("verifytxoutproof", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call verifytxoutproof: %v", err)
+	}
+
+	// Parse the result
+	var txids []string
+	if err := json.Unmarshal(resp.Result, &txids); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal verified txids: %v", err)
+	}
+
+	return txids, nil
+}
+
+// GetBlockFilter calls the getblockfilter RPC method
+// blockhash: hash of the block whose filter to retrieve
+// filtertype: filter type, empty for "basic" (the only type defined by BIP158)
+// Requires the node to run with -blockfilterindex
+func (c *Client) GetBlockFilter(blockhash string, filtertype string) (*GetBlockFilterResponse, error) {
+	// Prepare parameters
+	params := []interface{}{blockhash}
+	if filtertype != "" {
+		params = append(params, filtertype)
+	}
+
+	// Call the RPC method
+	resp, err := c.call("getblockfilter", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getblockfilter: %v", err)
+	}
+
+	// Parse the result
+	var result GetBlockFilterResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block filter: %v", err)
+	}
+
+	return &result, nil
+}
+
+// GetIndexInfo calls the getindexinfo RPC method
+// indexName: only report this index, such as "txindex" or "basic block filter index"; empty for all
+// Returns the enabled indexes keyed by name
+func (c *Client) GetIndexInfo(indexName string) (map[string]IndexInfo, error) {
+	// Prepare parameters
+	params := []interface{}{}
+	if indexName != "" {
+		params = append(params, indexName)
+	}
+
+	// Call the RPC method
+	resp, err := c.call("getindexinfo", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getindexinfo: %v", err)
+	}
+
+	// Parse the result
+	var result map[string]IndexInfo
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index info: %v", err)
+	}
+
+	return result, nil
+}
+
+// waitGrace is added to the node-side wait to get the HTTP timeout of a long-polling call
+const waitGrace = 10 * time.Second
+
+// WaitForNewBlock calls the waitfornewblock RPC method
+// Blocks until the tip changes or timeout expires and returns the tip at that point
+// timeout: how long the node should wait; 0 waits until a block arrives or ctx is done
+func (c *Client) WaitForNewBlock(ctx context.Context, timeout time.Duration) (*WaitForBlockResponse, error) {
+	return c.waitForBlock(ctx, "waitfornewblock", []interface{}{}, timeout)
+}
+
+// WaitForBlock calls the waitforblock RPC method
+// Blocks until blockhash is the tip or timeout expires and returns the tip at that point
+// blockhash: block hash to wait for
+// timeout: how long the node should wait; 0 waits until the block arrives or ctx is done
+func (c *Client) WaitForBlock(ctx context.Context, blockhash string, timeout time.Duration) (*WaitForBlockResponse, error) {
+	return c.waitForBlock(ctx, "waitforblock", []interface{}{blockhash}, timeout)
+}
+
+// WaitForBlockHeight calls the waitforblockheight RPC method
+// Blocks until the tip is at least height or timeout expires and returns the tip at that point
+// height: block height to wait for
+// timeout: how long the node should wait; 0 waits until the height is reached or ctx is done
+func (c *Client) WaitForBlockHeight(ctx context.Context, height int, timeout time.Duration) (*WaitForBlockResponse, error) {
+	return c.waitForBlock(ctx, "waitforblockheight", []interface{}{height}, timeout)
+}
+
+// waitForBlock performs one of the wait RPCs with a node-side timeout and a matching HTTP timeout
+func (c *Client) waitForBlock(ctx context.Context, method string, params []interface{}, timeout time.Duration) (*WaitForBlockResponse, error) {
+	// Never ask the node to wait longer than the caller is willing to,
+	// so it answers just before ctx expires instead of holding an RPC thread
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline) - time.Second
+		if remaining <= 0 {
+			remaining = time.Until(deadline) / 2
+		}
+		if remaining <= 0 {
+			return nil, ctx.Err()
+		}
+		if timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	// The node treats a timeout of 0 as no timeout
+	httpTimeout := noTimeout
+	if timeout > 0 {
+		timeout = max(timeout, time.Millisecond)
+		httpTimeout = timeout + waitGrace
+	}
+	params = append(params, timeout.Milliseconds())
+
+	// Call the RPC method
+	resp, err := c.callContext(ctx, method, params, "", httpTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	// Parse the result
+	var result WaitForBlockResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s result: %v", method, err)
+	}
+
+	return &result, nil
+}
+
 // GetRawTransaction calls the getrawtransaction RPC method
 // txid: transaction ID to retrieve
 // verbose: if false, return hex string; if true, return JSON object
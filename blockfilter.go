@@ -0,0 +1,102 @@
+package btcrpc
+
+import (
+	"fmt"
+
+	"github.com/koinvote/btcrpc/gcs"
+)
+
+// BlockFilterMatcher decides from BIP158 basic block filters whether a block touches any of a set of scripts
+//
+// A basic filter holds every output script a block creates and every script its inputs
+// spend, except OP_RETURN and empty scripts, so a match means the block may pay to or
+// spend from one of the scripts and is worth fetching with GetBlock. False positives
+// are rare (about 1 in 784931 per script), false negatives do not happen.
+//
+// Every filter is checked against the node's filter header chain: its header is
+// recomputed from the previous block's filter header and must equal the header the
+// node reports. The last header is remembered, so blocks matched in chain order cost
+// no extra call.
+type BlockFilterMatcher struct {
+	client  *Client
+	scripts [][]byte
+
+	lastHash   string
+	lastHeader [32]byte
+}
+
+// NewBlockFilterMatcher creates a matcher for the given scriptPubKeys
+// The node must run with -blockfilterindex.
+func NewBlockFilterMatcher(client *Client, scripts [][]byte) *BlockFilterMatcher {
+	m := &BlockFilterMatcher{client: client}
+	m.AddScripts(scripts...)
+	return m
+}
+
+// AddScripts adds scriptPubKeys to watch for
+func (m *BlockFilterMatcher) AddScripts(scripts ...[]byte) {
+	for _, script := range scripts {
+		m.scripts = append(m.scripts, append([]byte(nil), script...))
+	}
+}
+
+// Match reports whether the block with blockhash probably touches one of the scripts
+func (m *BlockFilterMatcher) Match(blockhash string) (bool, error) {
+	header, err := m.client.GetBlockHeader(blockhash)
+	if err != nil {
+		return false, err
+	}
+	filter, err := m.verifiedFilter(blockhash, header.PreviousBlockHash)
+	if err != nil {
+		return false, err
+	}
+
+	if len(m.scripts) == 0 {
+		return false, nil
+	}
+	key, err := gcs.KeyFromHex(blockhash)
+	if err != nil {
+		return false, err
+	}
+	return filter.MatchAny(key, m.scripts)
+}
+
+// verifiedFilter fetches the basic filter of a block and checks it against the filter header chain
+func (m *BlockFilterMatcher) verifiedFilter(blockhash, prevhash string) (*gcs.Filter, error) {
+	result, err := m.client.GetBlockFilter(blockhash, "basic")
+	if err != nil {
+		return nil, err
+	}
+	filter, err := gcs.NewBasicFilterHex(result.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// The genesis filter header is chained to the zero hash
+	var prevHeader [32]byte
+	switch {
+	case prevhash == "":
+	case prevhash == m.lastHash:
+		prevHeader = m.lastHeader
+	default:
+		prev, err := m.client.GetBlockFilter(prevhash, "basic")
+		if err != nil {
+			return nil, err
+		}
+		if prevHeader, err = gcs.ParseHash(prev.Header); err != nil {
+			return nil, err
+		}
+	}
+
+	expected, err := gcs.ParseHash(result.Header)
+	if err != nil {
+		return nil, err
+	}
+	if computed := filter.Header(prevHeader); computed != expected {
+		return nil, fmt.Errorf("filter header of block %s is %s, node reports %s", blockhash, gcs.HashToString(computed), result.Header)
+	}
+
+	m.lastHash = blockhash
+	m.lastHeader = expected
+	return filter, nil
+}
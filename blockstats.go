@@ -0,0 +1,143 @@
+package btcrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// blockStatsBatchSize is the number of getblockstats calls sent per batch by GetBlockStatsRange
+const blockStatsBatchSize = 100
+
+// seriesStats are the getblockstats fields AggregateBlockStats uses
+var seriesStats = []string{"height", "time", "txs", "totalfee", "total_weight", "total_size", "utxo_increase", "feerate_percentiles"}
+
+// StatsBucket aggregates the statistics of the blocks whose time falls in one bucket
+type StatsBucket struct {
+	Start         time.Time // start of the bucket, in UTC
+	FirstHeight   int
+	LastHeight    int
+	Blocks        int
+	Txs           int   // transactions, including coinbases
+	TotalFee      int64 // satoshis
+	TotalWeight   int64 // excluding coinbases
+	TotalSize     int64 // excluding coinbases
+	UTXOIncrease  int64
+	AvgFeeRate    float64 // sat/vB, total fees over total virtual size
+	MedianFeeRate float64 // sat/vB, median of the median fee rates of blocks with transactions
+}
+
+// GetBlockStatsRange calls getblockstats for every height from start to end inclusive, in batches
+// stats selects the statistics as for GetBlockStats. Results are in height order.
+func (c *Client) GetBlockStatsRange(ctx context.Context, start, end int, stats []string) ([]GetBlockStatsResponse, error) {
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid height range %d-%d", start, end)
+	}
+
+	results := make([]GetBlockStatsResponse, 0, end-start+1)
+	for from := start; from <= end; from += blockStatsBatchSize {
+		to := min(from+blockStatsBatchSize-1, end)
+
+		requests := make([]RPCRequest, 0, to-from+1)
+		for height := from; height <= to; height++ {
+			params := []interface{}{height}
+			if len(stats) > 0 {
+				params = append(params, stats)
+			}
+			requests = append(requests, RPCRequest{Method: "getblockstats", Params: params})
+		}
+
+		responses, err := c.callBatch(ctx, requests, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to call getblockstats: %v", err)
+		}
+		for i, resp := range responses {
+			if resp.Error != nil {
+				return nil, fmt.Errorf("failed to call getblockstats for height %d: %w", from+i, resp.Error)
+			}
+			var result GetBlockStatsResponse
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal block stats: %v", err)
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// BlockStatsSeries fetches the statistics of the blocks from start to end and aggregates them into time buckets
+// bucket is the bucket length, such as 24*time.Hour for daily series.
+func (c *Client) BlockStatsSeries(ctx context.Context, start, end int, bucket time.Duration) ([]StatsBucket, error) {
+	stats, err := c.GetBlockStatsRange(ctx, start, end, seriesStats)
+	if err != nil {
+		return nil, err
+	}
+	return AggregateBlockStats(stats, bucket), nil
+}
+
+// AggregateBlockStats groups block statistics into buckets of the given length by block time
+// Buckets are aligned to the Unix epoch, so daily buckets start at midnight UTC. Buckets without
+// blocks are omitted and the result is ordered by time. bucket defaults to one day.
+// The stats must include height, time, txs, totalfee, total_weight, total_size, utxo_increase
+// and feerate_percentiles; missing ones count as zero.
+func AggregateBlockStats(stats []GetBlockStatsResponse, bucket time.Duration) []StatsBucket {
+	if bucket <= 0 {
+		bucket = 24 * time.Hour
+	}
+	width := max(int64(bucket/time.Second), 1)
+
+	byStart := make(map[int64]*StatsBucket)
+	medians := make(map[int64][]int64)
+	for _, s := range stats {
+		key := s.Time - (s.Time%width+width)%width
+
+		b, ok := byStart[key]
+		if !ok {
+			b = &StatsBucket{Start: time.Unix(key, 0).UTC(), FirstHeight: s.Height, LastHeight: s.Height}
+			byStart[key] = b
+		}
+		b.FirstHeight = min(b.FirstHeight, s.Height)
+		b.LastHeight = max(b.LastHeight, s.Height)
+		b.Blocks++
+		b.Txs += s.Txs
+		b.TotalFee += s.TotalFee
+		b.TotalWeight += s.TotalWeight
+		b.TotalSize += s.TotalSize
+		b.UTXOIncrease += s.UTXOIncrease
+
+		// Blocks with only a coinbase report zero percentiles, which would drag the median down
+		if s.Txs > 1 && len(s.FeeRatePercentiles) == 5 {
+			medians[key] = append(medians[key], s.FeeRatePercentiles[2])
+		}
+	}
+
+	buckets := make([]StatsBucket, 0, len(byStart))
+	for key, b := range byStart {
+		if b.TotalWeight > 0 {
+			b.AvgFeeRate = float64(b.TotalFee) / (float64(b.TotalWeight) / 4)
+		}
+		b.MedianFeeRate = median(medians[key])
+		buckets = append(buckets, *b)
+	}
+	slices.SortFunc(buckets, func(a, b StatsBucket) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return buckets
+}
+
+// median returns the median of values, 0 when empty
+func median(values []int64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	slices.Sort(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return float64(values[mid])
+	}
+	return float64(values[mid-1]+values[mid]) / 2
+}
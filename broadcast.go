@@ -0,0 +1,234 @@
+package btcrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/koinvote/btcrpc/psbt"
+)
+
+// Rejection kinds reported through RejectError, for use with errors.Is
+var (
+	ErrInsufficientFee    = errors.New("insufficient fee")
+	ErrMissingInputs      = errors.New("missing or spent inputs")
+	ErrNonStandard        = errors.New("non-standard transaction")
+	ErrAlreadyKnown       = errors.New("transaction already known")
+	ErrMempoolConflict    = errors.New("conflicts with a mempool transaction")
+	ErrMaxFeeExceeded     = errors.New("fee exceeds the maximum fee rate")
+	ErrInvalidTransaction = errors.New("invalid transaction")
+	ErrRejected           = errors.New("transaction rejected")
+)
+
+// rejectReasons maps Bitcoin Core reject reason prefixes to rejection kinds, checked in order
+var rejectReasons = []struct {
+	prefix string
+	kind   error
+}{
+	{"txn-already-in-mempool", ErrAlreadyKnown},
+	{"txn-already-known", ErrAlreadyKnown},
+	{"txn-same-nonwitness-data-in-mempool", ErrAlreadyKnown},
+	{"transaction already in block chain", ErrAlreadyKnown},
+	{"transaction outputs already in utxo set", ErrAlreadyKnown},
+	{"missing-inputs", ErrMissingInputs},
+	{"bad-txns-inputs-missingorspent", ErrMissingInputs},
+	{"min relay fee not met", ErrInsufficientFee},
+	{"mempool min fee not met", ErrInsufficientFee},
+	{"insufficient fee", ErrInsufficientFee},
+	{"mempool full", ErrInsufficientFee},
+	{"max-fee-exceeded", ErrMaxFeeExceeded},
+	{"fee exceeds maximum", ErrMaxFeeExceeded},
+	{"txn-mempool-conflict", ErrMempoolConflict},
+	{"non-mandatory-script-verify-flag", ErrNonStandard},
+	{"bad-txns-nonstandard-inputs", ErrNonStandard},
+	{"bad-witness-nonstandard", ErrNonStandard},
+	{"scriptpubkey", ErrNonStandard},
+	{"scriptsig-size", ErrNonStandard},
+	{"scriptsig-not-pushonly", ErrNonStandard},
+	{"dust", ErrNonStandard},
+	{"tx-size", ErrNonStandard},
+	{"version", ErrNonStandard},
+	{"bare-multisig", ErrNonStandard},
+	{"multi-op-return", ErrNonStandard},
+	{"datacarrier", ErrNonStandard},
+	{"non-final", ErrNonStandard},
+	{"non-bip68-final", ErrNonStandard},
+	{"too-long-mempool-chain", ErrNonStandard},
+	{"mandatory-script-verify-flag-failed", ErrInvalidTransaction},
+	{"bad-", ErrInvalidTransaction},
+}
+
+// RejectError is returned when the node refuses a transaction
+// errors.Is(err, ErrInsufficientFee) and the other Err* kinds report why.
+type RejectError struct {
+	TxID    string // may be empty when the node did not report it
+	Reason  string // reject reason as reported by the node
+	Details string
+	Kind    error
+}
+
+// Error describes the rejection
+func (e *RejectError) Error() string {
+	msg := "transaction rejected: " + e.Reason
+	if e.TxID != "" {
+		msg = fmt.Sprintf("transaction %s rejected: %s", e.TxID, e.Reason)
+	}
+	if e.Details != "" {
+		msg += " (" + e.Details + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the rejection kind
+func (e *RejectError) Unwrap() error {
+	return e.Kind
+}
+
+// newRejectError classifies a reject reason
+func newRejectError(txid, reason, details string) *RejectError {
+	kind := ErrRejected
+	lower := strings.ToLower(reason)
+	for _, r := range rejectReasons {
+		if strings.HasPrefix(lower, r.prefix) {
+			kind = r.kind
+			break
+		}
+	}
+	return &RejectError{TxID: txid, Reason: reason, Details: details, Kind: kind}
+}
+
+// rejectErrorFromRPC turns a sendrawtransaction verification error into a RejectError
+// Other errors, such as connection failures, are returned unchanged.
+func rejectErrorFromRPC(txid string, err error) error {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+	switch rpcErr.Code {
+	case RPCErrVerify, RPCErrVerifyRejected, RPCErrVerifyAlreadyInChain:
+	default:
+		return err
+	}
+
+	reject := newRejectError(txid, rpcErr.Message, "")
+	if rpcErr.Code == RPCErrVerifyAlreadyInChain {
+		reject.Kind = ErrAlreadyKnown
+	}
+	return reject
+}
+
+// SendRawTransactionChecked runs testmempoolaccept before broadcasting and returns a *RejectError
+// if the node would refuse the transaction. Rejections reported by sendrawtransaction itself are
+// converted to *RejectError as well.
+func (c *Client) SendRawTransactionChecked(hexstring string, maxfeerate float64) (string, error) {
+	results, err := c.TestMempoolAccept([]string{hexstring}, maxfeerate)
+	if err != nil {
+		return "", err
+	}
+	if len(results) != 1 {
+		return "", fmt.Errorf("testmempoolaccept returned %d results for one transaction", len(results))
+	}
+
+	result := results[0]
+	if !result.Allowed {
+		return "", newRejectError(result.TxID, result.RejectReason, result.RejectDetails)
+	}
+
+	txid, err := c.SendRawTransaction(hexstring, maxfeerate)
+	if err != nil {
+		return "", rejectErrorFromRPC(result.TxID, err)
+	}
+	return txid, nil
+}
+
+// BroadcastIdempotent sends a transaction so that it is safe to retry
+//
+// The txid is computed locally from hexstring, so it is known even when the node
+// never answers. Transport failures and a warming-up node are retried with backoff
+// until ctx is done; any other error, such as a rejected login or a malformed
+// response, is returned at once. If an earlier attempt went through, the retry is
+// refused as already in the mempool or already in the chain; both count as success
+// and the txid is returned. Other rejections are returned as *RejectError.
+//
+// Once the transaction is confirmed and all its outputs are spent, a retry is refused
+// as spending missing inputs, like a conflicting spend would be. The txid is then looked
+// up with getmempoolentry, getrawtransaction and the default wallet's gettransaction;
+// finding it counts as success. A confirmed transaction is only found if the node runs
+// with -txindex or the transaction belongs to the default wallet.
+func (c *Client) BroadcastIdempotent(ctx context.Context, hexstring string, maxfeerate float64) (string, error) {
+	tx, err := psbt.DecodeTxHex(hexstring)
+	if err != nil {
+		return "", err
+	}
+	txid := tx.TxID()
+
+	params := []interface{}{hexstring}
+	if maxfeerate > 0 {
+		params = append(params, maxfeerate)
+	}
+
+	backoff := 250 * time.Millisecond
+	for {
+		_, err := c.callContext(ctx, "sendrawtransaction", params, "", 0)
+		if err == nil {
+			return txid, nil
+		}
+
+		err = rejectErrorFromRPC(txid, err)
+		if errors.Is(err, ErrAlreadyKnown) {
+			return txid, nil
+		}
+		if errors.Is(err, ErrMissingInputs) && c.transactionKnown(ctx, txid) {
+			return txid, nil
+		}
+		if !retryableBroadcastError(err) {
+			return txid, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return txid, fmt.Errorf("broadcast of %s not confirmed by the node: %w", txid, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 5*time.Second)
+	}
+}
+
+// transactionKnown reports whether the node has txid in its mempool, its transaction index or the default wallet
+func (c *Client) transactionKnown(ctx context.Context, txid string) bool {
+	lookups := []struct {
+		method string
+		params []interface{}
+	}{
+		{"getmempoolentry", []interface{}{txid}},
+		{"getrawtransaction", []interface{}{txid, false}},
+		{"gettransaction", []interface{}{txid}},
+	}
+	for _, l := range lookups {
+		if _, err := c.callContext(ctx, l.method, l.params, "", 0); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableBroadcastError reports whether err leaves the broadcast outcome unknown in a way a retry can resolve
+// That is a failure to reach the node or to get its answer, or the node still warming up.
+func retryableBroadcastError(err error) bool {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == RPCErrInWarmup
+	}
+
+	// Dial, read and write failures, including connection refused and reset
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -2,10 +2,12 @@ package btcrpc
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 // Client represents a Bitcoin Core RPC client
@@ -26,6 +28,15 @@ func NewClient(url, username, password string) *Client {
 	}
 }
 
+// SetTimeout sets the HTTP timeout for ordinary calls (0, the default, means no timeout)
+// Long-polling calls such as WaitForNewBlock replace it with one that covers the requested wait.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.client.Timeout = timeout
+}
+
+// noTimeout makes callContext ignore the HTTP client timeout and rely on the context alone
+const noTimeout time.Duration = -1
+
 // call performs a JSON-RPC call to Bitcoin Core
 func (c *Client) call(method string, params []interface{}) (*RPCResponse, error) {
 	return c.callWithWallet(method, params, "")
@@ -33,6 +44,27 @@ func (c *Client) call(method string, params []interface{}) (*RPCResponse, error)
 
 // callWithWallet performs a JSON-RPC call to Bitcoin Core with specific wallet
 func (c *Client) callWithWallet(method string, params []interface{}, walletName string) (*RPCResponse, error) {
+	return c.callContext(context.Background(), method, params, walletName, 0)
+}
+
+// callContext performs a JSON-RPC call that is aborted when ctx is done
+// A timeout of 0 keeps the HTTP client timeout. A positive timeout, or noTimeout, replaces
+// it for this call, for RPCs that are expected to block on the node side.
+func (c *Client) callContext(ctx context.Context, method string, params []interface{}, walletName string, timeout time.Duration) (*RPCResponse, error) {
+	httpClient := c.client
+	if timeout != 0 {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		// The context now bounds the call, so drop the client-wide timeout
+		longPoll := *c.client
+		longPoll.Timeout = 0
+		httpClient = &longPoll
+	}
+
 	// Create RPC request
 	rpcReq := RPCRequest{
 		Method:  method,
@@ -47,6 +79,76 @@ func (c *Client) callWithWallet(method string, params []interface{}, walletName
 		return nil, fmt.Errorf("failed to marshal request: %v", err)
 	}
 
+	body, err := c.post(ctx, httpClient, walletName, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse JSON-RPC response
+	var rpcResp RPCResponse
+	if err := json.Unmarshal(body, &rpcResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	// Check for RPC error
+	if rpcResp.Error != nil {
+		return nil, rpcResp.Error
+	}
+
+	return &rpcResp, nil
+}
+
+// callBatch performs several JSON-RPC calls in a single HTTP request
+// Responses are returned in the order of requests. A failed call does not fail the batch,
+// its response carries the error instead. Request IDs are assigned here.
+func (c *Client) callBatch(ctx context.Context, requests []RPCRequest, walletName string) ([]RPCResponse, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	// Number the requests so responses can be matched whatever order they come back in
+	batch := make([]RPCRequest, len(requests))
+	for i, req := range requests {
+		req.ID = i
+		req.JsonRPC = "1.0"
+		batch[i] = req
+	}
+
+	// Serialize request to JSON
+	reqBody, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch request: %v", err)
+	}
+
+	body, err := c.post(ctx, c.client, walletName, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse JSON-RPC responses
+	var responses []RPCResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal batch response: %v", err)
+	}
+	if len(responses) != len(requests) {
+		return nil, fmt.Errorf("batch of %d requests returned %d responses", len(requests), len(responses))
+	}
+
+	ordered := make([]RPCResponse, len(requests))
+	seen := make([]bool, len(requests))
+	for _, resp := range responses {
+		if resp.ID < 0 || resp.ID >= len(requests) || seen[resp.ID] {
+			return nil, fmt.Errorf("batch response has unexpected id %d", resp.ID)
+		}
+		ordered[resp.ID] = resp
+		seen[resp.ID] = true
+	}
+
+	return ordered, nil
+}
+
+// post sends a JSON-RPC request body and returns the response body
+func (c *Client) post(ctx context.Context, httpClient *http.Client, walletName string, reqBody []byte) ([]byte, error) {
 	// Create URL with wallet endpoint if needed
 	url := c.url
 	if walletName != "" {
@@ -54,7 +156,7 @@ func (c *Client) callWithWallet(method string, params []interface{}, walletName
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
 	}
@@ -64,33 +166,27 @@ func (c *Client) callWithWallet(method string, params []interface{}, walletName
 	req.SetBasicAuth(c.username, c.password)
 
 	// Perform HTTP request
-	resp, err := c.client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to perform HTTP request: %v", err)
+		return nil, fmt.Errorf("failed to perform HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// Check HTTP status
+	// Bitcoin Core reports RPC errors with a non-200 status and the error in the body
 	if resp.StatusCode != http.StatusOK {
+		var errResp RPCResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			return nil, errResp.Error
+		}
 		return nil, fmt.Errorf("HTTP error: %s, body: %s", resp.Status, string(body))
 	}
 
-	// Parse JSON-RPC response
-	var rpcResp RPCResponse
-	if err := json.Unmarshal(body, &rpcResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
-	}
-
-	// Check for RPC error
-	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("RPC error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
-	}
-
-	return &rpcResp, nil
+	return body, nil
 }
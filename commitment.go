@@ -0,0 +1,345 @@
+package btcrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// DefaultMaxDataSize is the largest OP_RETURN payload relayed by default before Bitcoin Core 30
+const DefaultMaxDataSize = 80
+
+// CommitmentPublisher anchors payloads on-chain in OP_RETURN outputs funded by a wallet
+type CommitmentPublisher struct {
+	client     *Client
+	walletName string
+	prefix     []byte
+
+	// FeeRate is the fee rate in sat/vB, 0 lets the wallet estimate it
+	FeeRate float64
+	// ConfTarget is the confirmation target used when FeeRate is 0
+	ConfTarget int
+	// MaxDataSize bounds prefix plus payload (default DefaultMaxDataSize)
+	MaxDataSize int
+}
+
+// NewCommitmentPublisher creates a publisher that spends from walletName and prepends prefix to every payload
+func NewCommitmentPublisher(client *Client, walletName string, prefix []byte) *CommitmentPublisher {
+	return &CommitmentPublisher{
+		client:      client,
+		walletName:  walletName,
+		prefix:      append([]byte(nil), prefix...),
+		MaxDataSize: DefaultMaxDataSize,
+	}
+}
+
+// Publish builds, funds, signs and broadcasts a transaction carrying prefix+payload and returns its txid
+func (p *CommitmentPublisher) Publish(payload []byte) (string, error) {
+	data := append(append([]byte(nil), p.prefix...), payload...)
+	if p.MaxDataSize > 0 && len(data) > p.MaxDataSize {
+		return "", fmt.Errorf("commitment of %d bytes exceeds the %d byte limit", len(data), p.MaxDataSize)
+	}
+
+	raw, err := p.client.CreateRawTransaction([]CreateRawTransactionInput{}, CreateRawTransactionOutputs{DataOutput(data)}, 0, false)
+	if err != nil {
+		return "", err
+	}
+
+	options := &FundOptions{FeeRate: p.FeeRate}
+	if p.FeeRate == 0 {
+		options.ConfTarget = p.ConfTarget
+	}
+	funded, err := p.client.FundRawTransaction(p.walletName, raw, options, nil)
+	if err != nil {
+		return "", err
+	}
+
+	signed, err := p.client.SignRawTransactionWithWallet(p.walletName, funded.Hex, nil, "")
+	if err != nil {
+		return "", err
+	}
+	if !signed.Complete {
+		return "", fmt.Errorf("wallet %s could not sign the commitment transaction", p.walletName)
+	}
+
+	return p.client.SendRawTransaction(signed.Hex, 0)
+}
+
+// Confirmations returns the number of confirmations of a published commitment
+// A negative value means the transaction conflicts with the chain.
+func (p *CommitmentPublisher) Confirmations(txid string) (int, error) {
+	tx, err := p.client.GetTransaction(p.walletName, txid, false, false)
+	if err != nil {
+		return 0, err
+	}
+	return tx.Confirmations, nil
+}
+
+// WaitConfirmed polls every interval until txid has at least confirmations confirmations
+func (p *CommitmentPublisher) WaitConfirmed(ctx context.Context, txid string, confirmations int, interval time.Duration) (*GetTransactionResponse, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		tx, err := p.client.GetTransaction(p.walletName, txid, false, false)
+		if err != nil {
+			return nil, err
+		}
+		if tx.Confirmations >= confirmations {
+			return tx, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Commitment is an OP_RETURN output carrying the scanner's prefix
+type Commitment struct {
+	TxID      string
+	Vout      int
+	Height    int
+	BlockHash string
+	Payload   []byte // data after the prefix
+}
+
+// CommitmentEventType identifies the kind of change reported by a CommitmentScanner
+type CommitmentEventType int
+
+const (
+	// CommitmentFound is emitted for a commitment in a newly connected block
+	CommitmentFound CommitmentEventType = iota
+	// CommitmentRemoved is emitted for a commitment whose block was disconnected by a reorg
+	CommitmentRemoved
+)
+
+// String returns a readable name for the event type
+func (t CommitmentEventType) String() string {
+	switch t {
+	case CommitmentFound:
+		return "found"
+	case CommitmentRemoved:
+		return "removed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+// CommitmentEvent is a single commitment change
+type CommitmentEvent struct {
+	Type       CommitmentEventType
+	Commitment Commitment
+}
+
+// scannedBlock is a block the scanner has connected, kept to detect reorgs
+type scannedBlock struct {
+	hash        string
+	height      int
+	commitments []Commitment
+}
+
+// CommitmentScanner walks the chain with GetBlockVerbose and extracts prefixed OP_RETURN payloads
+//
+// The last MaxReorgDepth connected blocks are remembered, together with the block
+// before them as an anchor, which is also where a resumed scanner starts checking from.
+// When a new block does not build on the last one, blocks are disconnected one by one,
+// their commitments are reported as removed, and scanning resumes from the fork point.
+type CommitmentScanner struct {
+	client *Client
+	prefix []byte
+	next   int
+	recent []scannedBlock
+
+	// MaxReorgDepth is the number of blocks kept for reorg handling (default 100)
+	MaxReorgDepth int
+}
+
+// NewCommitmentScanner creates a scanner that starts at startHeight
+func NewCommitmentScanner(client *Client, prefix []byte, startHeight int) *CommitmentScanner {
+	return &CommitmentScanner{
+		client:        client,
+		prefix:        append([]byte(nil), prefix...),
+		next:          startHeight,
+		MaxReorgDepth: 100,
+	}
+}
+
+// NextHeight returns the height of the next block to scan, which can be saved to resume later
+func (s *CommitmentScanner) NextHeight() int {
+	return s.next
+}
+
+// Scan processes every block up to the current tip, passing events to handle in chain order
+func (s *CommitmentScanner) Scan(handle func(CommitmentEvent) error) error {
+	info, err := s.client.GetBlockchainInfo()
+	if err != nil {
+		return err
+	}
+
+	// Without an anchor a reorg of the first scanned block could not be detected
+	if len(s.recent) == 0 && s.next > 0 && s.next <= int(info.Blocks) {
+		hash, err := s.client.GetBlockHash(s.next - 1)
+		if err != nil {
+			return err
+		}
+		s.recent = append(s.recent, scannedBlock{hash: hash, height: s.next - 1})
+	}
+
+	for s.next <= int(info.Blocks) {
+		hash, err := s.client.GetBlockHash(s.next)
+		if err != nil {
+			return err
+		}
+		block, err := s.client.GetBlockVerbose(hash)
+		if err != nil {
+			return err
+		}
+
+		if n := len(s.recent); n > 0 && block.PreviousBlockHash != s.recent[n-1].hash {
+			if err := s.disconnect(handle); err != nil {
+				return err
+			}
+			continue
+		}
+
+		scanned := scannedBlock{hash: block.Hash, height: block.Height, commitments: s.extract(block)}
+		for _, c := range scanned.commitments {
+			if err := handle(CommitmentEvent{Type: CommitmentFound, Commitment: c}); err != nil {
+				return err
+			}
+		}
+
+		s.recent = append(s.recent, scanned)
+		if len(s.recent) > s.MaxReorgDepth+1 {
+			s.recent = s.recent[len(s.recent)-s.MaxReorgDepth-1:]
+		}
+		s.next = block.Height + 1
+	}
+
+	return nil
+}
+
+// Run calls Scan every interval until ctx is cancelled or a scan fails
+func (s *CommitmentScanner) Run(ctx context.Context, interval time.Duration, handle func(CommitmentEvent) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Scan(handle); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// disconnect drops the last connected block and reports its commitments as removed
+func (s *CommitmentScanner) disconnect(handle func(CommitmentEvent) error) error {
+	last := s.recent[len(s.recent)-1]
+	// The oldest block only anchors the window, the block before it is unknown
+	if len(s.recent) == 1 {
+		return fmt.Errorf("reorg at height %d reaches below the oldest remembered block", last.height)
+	}
+
+	// Undo in reverse order of discovery
+	for i := len(last.commitments) - 1; i >= 0; i-- {
+		if err := handle(CommitmentEvent{Type: CommitmentRemoved, Commitment: last.commitments[i]}); err != nil {
+			return err
+		}
+	}
+
+	s.recent = s.recent[:len(s.recent)-1]
+	s.next = last.height
+	return nil
+}
+
+// extract returns the prefixed OP_RETURN outputs of a block
+func (s *CommitmentScanner) extract(block *GetBlockVerboseResponse) []Commitment {
+	var commitments []Commitment
+	for _, tx := range block.Tx {
+		for _, out := range tx.Vout {
+			if out.ScriptPubKey.Type != "nulldata" {
+				continue
+			}
+			script, err := hex.DecodeString(out.ScriptPubKey.Hex)
+			if err != nil {
+				continue
+			}
+			data, ok := nullData(script)
+			if !ok || !bytes.HasPrefix(data, s.prefix) {
+				continue
+			}
+			commitments = append(commitments, Commitment{
+				TxID:      tx.TxID,
+				Vout:      out.N,
+				Height:    block.Height,
+				BlockHash: block.Hash,
+				Payload:   data[len(s.prefix):],
+			})
+		}
+	}
+	return commitments
+}
+
+// nullData concatenates the data pushes following OP_RETURN
+// It reports false if the script is not OP_RETURN followed only by pushes.
+func nullData(script []byte) ([]byte, bool) {
+	if len(script) == 0 || script[0] != 0x6a {
+		return nil, false
+	}
+
+	var data []byte
+	for i := 1; i < len(script); {
+		op := script[i]
+		i++
+
+		var n int
+		switch {
+		case op == 0x00:
+			continue
+		case op <= 0x4b:
+			n = int(op)
+		case op == 0x4c:
+			if i+1 > len(script) {
+				return nil, false
+			}
+			n = int(script[i])
+			i++
+		case op == 0x4d:
+			if i+2 > len(script) {
+				return nil, false
+			}
+			n = int(binary.LittleEndian.Uint16(script[i:]))
+			i += 2
+		case op == 0x4e:
+			if i+4 > len(script) {
+				return nil, false
+			}
+			n = int(binary.LittleEndian.Uint32(script[i:]))
+			i += 4
+		case op >= 0x51 && op <= 0x60:
+			// OP_1..OP_16 push a small number
+			data = append(data, op-0x50)
+			continue
+		default:
+			return nil, false
+		}
+
+		if n < 0 || i+n > len(script) {
+			return nil, false
+		}
+		data = append(data, script[i:i+n]...)
+		i += n
+	}
+	return data, true
+}
@@ -0,0 +1,142 @@
+package descriptor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Base58 address version bytes for mainnet and testnet/regtest
+var base58AddressVersions = map[byte]bool{
+	0x00: true, // P2PKH mainnet
+	0x05: true, // P2SH mainnet
+	0x6f: true, // P2PKH testnet
+	0xc4: true, // P2SH testnet
+}
+
+// Segwit address human readable parts
+var segwitHRPs = map[string]bool{
+	"bc":   true,
+	"tb":   true,
+	"bcrt": true,
+}
+
+// bech32 checksum constants (BIP173 and BIP350)
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// validateAddress checks that s is a well-formed base58 or segwit address
+func validateAddress(s string) error {
+	if s == "" {
+		return errors.New("empty address")
+	}
+	if i := strings.LastIndexByte(s, '1'); i > 0 && segwitHRPs[strings.ToLower(s[:i])] {
+		return validateSegwitAddress(s)
+	}
+	payload, err := decodeBase58Check(s)
+	if err != nil {
+		return err
+	}
+	if len(payload) != 21 || !base58AddressVersions[payload[0]] {
+		return errors.New("unknown base58 address type")
+	}
+	return nil
+}
+
+// validateSegwitAddress decodes a bech32/bech32m address and checks the witness program rules
+func validateSegwitAddress(s string) error {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return errors.New("mixed case bech32 address")
+	}
+	s = strings.ToLower(s)
+	if len(s) > 90 {
+		return errors.New("bech32 address too long")
+	}
+	sep := strings.LastIndexByte(s, '1')
+	hrp, data := s[:sep], s[sep+1:]
+	if len(data) < 7 {
+		return errors.New("bech32 data part too short")
+	}
+
+	values := make([]byte, len(data))
+	for i := 0; i < len(data); i++ {
+		v := strings.IndexByte(checksumCharset, data[i])
+		if v < 0 {
+			return fmt.Errorf("invalid bech32 character %q", data[i])
+		}
+		values[i] = byte(v)
+	}
+
+	witnessVersion := values[0]
+	constant := bech32Polymod(append(bech32ExpandHRP(hrp), values...))
+	switch {
+	case witnessVersion == 0 && constant != bech32Const:
+		return errors.New("invalid bech32 checksum")
+	case witnessVersion > 0 && constant != bech32mConst:
+		return errors.New("invalid bech32m checksum")
+	case witnessVersion > 16:
+		return fmt.Errorf("invalid witness version %d", witnessVersion)
+	}
+
+	program, err := convertBits(values[1:len(values)-6], 5, 8)
+	if err != nil {
+		return err
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return fmt.Errorf("invalid witness program length %d", len(program))
+	}
+	if witnessVersion == 0 && len(program) != 20 && len(program) != 32 {
+		return fmt.Errorf("invalid witness v0 program length %d", len(program))
+	}
+	return nil
+}
+
+// bech32Polymod computes the bech32 checksum state over values
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 != 0 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32ExpandHRP expands the human readable part for checksum computation
+func bech32ExpandHRP(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// convertBits regroups 5-bit values into bytes, rejecting non-zero padding
+func convertBits(data []byte, from, to uint) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	var out []byte
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if bits >= from || (acc<<(to-bits))&maxv != 0 {
+		return nil, errors.New("invalid bech32 padding")
+	}
+	return out, nil
+}
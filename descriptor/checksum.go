@@ -0,0 +1,119 @@
+package descriptor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inputCharset is the character set descriptors may use, ordered so that the checksum
+// catches common case and symbol mistakes (see Bitcoin Core's descriptor.cpp)
+const inputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+	"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+	"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+// checksumCharset is the bech32 character set used to render checksums
+const checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// ChecksumLength is the number of characters in a descriptor checksum
+const ChecksumLength = 8
+
+// polyMod updates the BCH checksum state c with the 5-bit value val
+func polyMod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// Checksum computes the 8-character checksum of a descriptor without its "#checksum" suffix
+func Checksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for i := 0; i < len(desc); i++ {
+		pos := strings.IndexByte(inputCharset, desc[i])
+		if pos < 0 {
+			return "", &ParseError{Pos: i, Msg: fmt.Sprintf("invalid character %q", desc[i])}
+		}
+		// Emit a symbol for the position inside the group, for every character
+		c = polyMod(c, pos&31)
+		// Accumulate the group numbers
+		cls = cls*3 + (pos >> 5)
+		clsCount++
+		if clsCount == 3 {
+			// Emit an extra symbol representing the group numbers, for every 3 characters
+			c = polyMod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = polyMod(c, cls)
+	}
+	// Shift further to determine the checksum
+	for j := 0; j < ChecksumLength; j++ {
+		c = polyMod(c, 0)
+	}
+	// Prevent appending zeroes from not affecting the checksum
+	c ^= 1
+
+	var sb strings.Builder
+	for j := 0; j < ChecksumLength; j++ {
+		sb.WriteByte(checksumCharset[(c>>(5*(7-j)))&31])
+	}
+	return sb.String(), nil
+}
+
+// AddChecksum returns desc with its checksum appended, replacing any checksum already present
+func AddChecksum(desc string) (string, error) {
+	body, _ := SplitChecksum(desc)
+	sum, err := Checksum(body)
+	if err != nil {
+		return "", err
+	}
+	return body + "#" + sum, nil
+}
+
+// SplitChecksum separates a descriptor into its body and checksum (empty if absent)
+func SplitChecksum(desc string) (body, checksum string) {
+	if i := strings.LastIndexByte(desc, '#'); i >= 0 {
+		return desc[:i], desc[i+1:]
+	}
+	return desc, ""
+}
+
+// VerifyChecksum checks that desc carries a checksum and that it matches the descriptor body
+func VerifyChecksum(desc string) error {
+	body, sum := SplitChecksum(desc)
+	if len(body) == len(desc) {
+		return &ParseError{Pos: len(desc), Msg: "missing checksum"}
+	}
+	return verifyChecksum(body, sum)
+}
+
+// verifyChecksum compares sum with the checksum computed over body
+func verifyChecksum(body, sum string) error {
+	if len(sum) != ChecksumLength {
+		return &ParseError{Pos: len(body) + 1, Msg: fmt.Sprintf("checksum %q is not %d characters", sum, ChecksumLength)}
+	}
+	expected, err := Checksum(body)
+	if err != nil {
+		return err
+	}
+	if sum != expected {
+		return &ParseError{Pos: len(body) + 1, Msg: fmt.Sprintf("checksum %q does not match, expected %q", sum, expected)}
+	}
+	return nil
+}
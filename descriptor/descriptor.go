@@ -0,0 +1,291 @@
+// Package descriptor parses, validates and renders Bitcoin output script descriptors
+// (BIP380-386) without talking to a node, so descriptors can be checked before they
+// are sent to importdescriptors or deriveaddresses.
+package descriptor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseError describes why a descriptor could not be parsed and where
+type ParseError struct {
+	Pos int    // byte offset into the descriptor string
+	Msg string // human readable reason
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("descriptor: %s at position %d", e.Msg, e.Pos)
+}
+
+// ScriptType is the name of a descriptor script function
+type ScriptType string
+
+// Script functions understood by the parser
+const (
+	TypePK           ScriptType = "pk"
+	TypePKH          ScriptType = "pkh"
+	TypeWPKH         ScriptType = "wpkh"
+	TypeSH           ScriptType = "sh"
+	TypeWSH          ScriptType = "wsh"
+	TypeTR           ScriptType = "tr"
+	TypeCombo        ScriptType = "combo"
+	TypeMulti        ScriptType = "multi"
+	TypeSortedMulti  ScriptType = "sortedmulti"
+	TypeMultiA       ScriptType = "multi_a"
+	TypeSortedMultiA ScriptType = "sortedmulti_a"
+	TypeAddr         ScriptType = "addr"
+	TypeRaw          ScriptType = "raw"
+)
+
+const (
+	// maxTapTreeDepth is the deepest taproot script tree allowed by BIP341
+	maxTapTreeDepth = 128
+	// hardenedKeyStart is the first hardened BIP32 child index
+	hardenedKeyStart uint32 = 0x80000000
+	// defaultHardenMark is used when rendering hardened path steps
+	defaultHardenMark = "'"
+)
+
+// Descriptor is a parsed output descriptor
+// Which fields are set depends on Type:
+//   - pk, pkh, wpkh, combo: Keys holds one key
+//   - multi, sortedmulti, multi_a, sortedmulti_a: Threshold and Keys
+//   - sh, wsh: Sub holds the wrapped script
+//   - tr: Keys holds the internal key and Tree the optional script tree
+//   - addr: Address
+//   - raw: Script
+type Descriptor struct {
+	Type      ScriptType
+	Keys      []*Key
+	Threshold int
+	Sub       *Descriptor
+	Tree      *TapTree
+	Address   string
+	Script    []byte
+}
+
+// TapTree is a node of a taproot script tree: either a leaf script or a pair of branches
+type TapTree struct {
+	Leaf        *Descriptor
+	Left, Right *TapTree
+}
+
+// String renders the canonical descriptor without checksum
+func (d *Descriptor) String() string {
+	var sb strings.Builder
+	d.write(&sb)
+	return sb.String()
+}
+
+// StringWithChecksum renders the canonical descriptor followed by "#" and its checksum
+// It fails if a hand-built descriptor holds characters the checksum cannot cover.
+func (d *Descriptor) StringWithChecksum() (string, error) {
+	s := d.String()
+	sum, err := Checksum(s)
+	if err != nil {
+		return "", err
+	}
+	return s + "#" + sum, nil
+}
+
+// IsRange reports whether any key of the descriptor ends in a /* wildcard
+func (d *Descriptor) IsRange() bool {
+	found := false
+	d.walkKeys(func(k *Key) {
+		if k.Wildcard != WildcardNone {
+			found = true
+		}
+	})
+	return found
+}
+
+// HasPrivateKeys reports whether the descriptor contains any WIF or extended private key
+func (d *Descriptor) HasPrivateKeys() bool {
+	found := false
+	d.walkKeys(func(k *Key) {
+		if k.IsPrivate() {
+			found = true
+		}
+	})
+	return found
+}
+
+// AllKeys returns every key of the descriptor in the order it appears
+func (d *Descriptor) AllKeys() []*Key {
+	var keys []*Key
+	d.walkKeys(func(k *Key) { keys = append(keys, k) })
+	return keys
+}
+
+// walkKeys calls fn for every key, depth first in textual order
+func (d *Descriptor) walkKeys(fn func(*Key)) {
+	for _, k := range d.Keys {
+		fn(k)
+	}
+	if d.Sub != nil {
+		d.Sub.walkKeys(fn)
+	}
+	if d.Tree != nil {
+		d.Tree.walkKeys(fn)
+	}
+}
+
+// walkKeys calls fn for every key of the leaves below t
+func (t *TapTree) walkKeys(fn func(*Key)) {
+	if t.Leaf != nil {
+		t.Leaf.walkKeys(fn)
+		return
+	}
+	t.Left.walkKeys(fn)
+	t.Right.walkKeys(fn)
+}
+
+// write renders d into sb
+func (d *Descriptor) write(sb *strings.Builder) {
+	sb.WriteString(string(d.Type))
+	sb.WriteByte('(')
+	switch d.Type {
+	case TypeSH, TypeWSH:
+		d.Sub.write(sb)
+	case TypeMulti, TypeSortedMulti, TypeMultiA, TypeSortedMultiA:
+		sb.WriteString(strconv.Itoa(d.Threshold))
+		for _, k := range d.Keys {
+			sb.WriteByte(',')
+			sb.WriteString(k.String())
+		}
+	case TypeTR:
+		sb.WriteString(d.Keys[0].String())
+		if d.Tree != nil {
+			sb.WriteByte(',')
+			d.Tree.write(sb)
+		}
+	case TypeAddr:
+		sb.WriteString(d.Address)
+	case TypeRaw:
+		sb.WriteString(hex.EncodeToString(d.Script))
+	default:
+		sb.WriteString(d.Keys[0].String())
+	}
+	sb.WriteByte(')')
+}
+
+// write renders the tree into sb
+func (t *TapTree) write(sb *strings.Builder) {
+	if t.Leaf != nil {
+		t.Leaf.write(sb)
+		return
+	}
+	sb.WriteByte('{')
+	t.Left.write(sb)
+	sb.WriteByte(',')
+	t.Right.write(sb)
+	sb.WriteByte('}')
+}
+
+// Path is a BIP32 derivation path; hardened steps have the 0x80000000 bit set
+type Path []uint32
+
+// String renders the path as "/"-separated steps using ' for hardened steps
+func (p Path) String() string {
+	var sb strings.Builder
+	for i, step := range p {
+		if i > 0 {
+			sb.WriteByte('/')
+		}
+		if step >= hardenedKeyStart {
+			sb.WriteString(strconv.FormatUint(uint64(step-hardenedKeyStart), 10))
+			sb.WriteString(defaultHardenMark)
+		} else {
+			sb.WriteString(strconv.FormatUint(uint64(step), 10))
+		}
+	}
+	return sb.String()
+}
+
+// KeyOrigin is the [fingerprint/path] prefix recording where a key was derived from
+type KeyOrigin struct {
+	Fingerprint [4]byte
+	Path        Path
+}
+
+// String renders the origin including its brackets
+func (o *KeyOrigin) String() string {
+	s := "[" + hex.EncodeToString(o.Fingerprint[:])
+	if len(o.Path) > 0 {
+		s += "/" + o.Path.String()
+	}
+	return s + "]"
+}
+
+// KeyKind identifies how a key expression encodes its key material
+type KeyKind int
+
+const (
+	// KeyPublicHex is a hex encoded compressed or uncompressed public key
+	KeyPublicHex KeyKind = iota
+	// KeyXOnlyHex is a hex encoded 32-byte x-only public key (taproot only)
+	KeyXOnlyHex
+	// KeyWIF is a WIF encoded private key
+	KeyWIF
+	// KeyExtendedPublic is a base58 xpub/tpub
+	KeyExtendedPublic
+	// KeyExtendedPrivate is a base58 xprv/tprv
+	KeyExtendedPrivate
+)
+
+// Wildcard describes the trailing /* of a ranged key
+type Wildcard int
+
+const (
+	// WildcardNone means the key is not ranged
+	WildcardNone Wildcard = iota
+	// WildcardUnhardened is a trailing /*
+	WildcardUnhardened
+	// WildcardHardened is a trailing /*' or /*h
+	WildcardHardened
+)
+
+// Key is a key expression inside a descriptor
+type Key struct {
+	Origin   *KeyOrigin
+	Kind     KeyKind
+	Encoded  string       // key material exactly as written (hex, WIF or base58 extended key)
+	PubKey   []byte       // decoded public key for KeyPublicHex and KeyXOnlyHex
+	Extended *ExtendedKey // decoded extended key for KeyExtendedPublic and KeyExtendedPrivate
+	Path     Path         // derivation steps after an extended key
+	Wildcard Wildcard
+}
+
+// IsPrivate reports whether the key carries private key material
+func (k *Key) IsPrivate() bool {
+	return k.Kind == KeyWIF || k.Kind == KeyExtendedPrivate
+}
+
+// String renders the key expression
+func (k *Key) String() string {
+	var sb strings.Builder
+	if k.Origin != nil {
+		sb.WriteString(k.Origin.String())
+	}
+	switch k.Kind {
+	case KeyPublicHex, KeyXOnlyHex:
+		sb.WriteString(hex.EncodeToString(k.PubKey))
+	default:
+		sb.WriteString(k.Encoded)
+	}
+	if len(k.Path) > 0 {
+		sb.WriteByte('/')
+		sb.WriteString(k.Path.String())
+	}
+	switch k.Wildcard {
+	case WildcardUnhardened:
+		sb.WriteString("/*")
+	case WildcardHardened:
+		sb.WriteString("/*" + defaultHardenMark)
+	}
+	return sb.String()
+}
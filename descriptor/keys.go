@@ -0,0 +1,333 @@
+package descriptor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Extended key version bytes (BIP32)
+var (
+	versionXpub = [4]byte{0x04, 0x88, 0xb2, 0x1e}
+	versionXprv = [4]byte{0x04, 0x88, 0xad, 0xe4}
+	versionTpub = [4]byte{0x04, 0x35, 0x87, 0xcf}
+	versionTprv = [4]byte{0x04, 0x35, 0x83, 0x94}
+)
+
+// ExtendedKey is a decoded BIP32 extended key
+type ExtendedKey struct {
+	Version           [4]byte
+	Depth             uint8
+	ParentFingerprint [4]byte
+	ChildNumber       uint32
+	ChainCode         [32]byte
+	KeyData           [33]byte // compressed public key, or 0x00 followed by the private key
+}
+
+// IsPrivate reports whether the extended key is an xprv/tprv
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.Version == versionXprv || k.Version == versionTprv
+}
+
+// IsTestnet reports whether the extended key uses testnet version bytes
+func (k *ExtendedKey) IsTestnet() bool {
+	return k.Version == versionTpub || k.Version == versionTprv
+}
+
+// ParseExtendedKey decodes and validates a base58 xpub, xprv, tpub or tprv
+func ParseExtendedKey(s string) (*ExtendedKey, error) {
+	payload, err := decodeBase58Check(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) != 78 {
+		return nil, fmt.Errorf("extended key is %d bytes, expected 78", len(payload))
+	}
+
+	var k ExtendedKey
+	copy(k.Version[:], payload[0:4])
+	k.Depth = payload[4]
+	copy(k.ParentFingerprint[:], payload[5:9])
+	k.ChildNumber = binary.BigEndian.Uint32(payload[9:13])
+	copy(k.ChainCode[:], payload[13:45])
+	copy(k.KeyData[:], payload[45:78])
+
+	switch k.Version {
+	case versionXpub, versionTpub:
+		if err := checkPubKey(k.KeyData[:]); err != nil {
+			return nil, fmt.Errorf("extended public key: %v", err)
+		}
+	case versionXprv, versionTprv:
+		if k.KeyData[0] != 0x00 {
+			return nil, errors.New("extended private key data must start with 0x00")
+		}
+		if err := checkPrivKey(k.KeyData[1:]); err != nil {
+			return nil, fmt.Errorf("extended private key: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown extended key version %x", k.Version)
+	}
+
+	if k.Depth == 0 && (k.ParentFingerprint != [4]byte{} || k.ChildNumber != 0) {
+		return nil, errors.New("extended key at depth 0 must have zero parent fingerprint and child number")
+	}
+
+	return &k, nil
+}
+
+// parseKey parses a key expression; ctx restricts which public key encodings are allowed
+func parseKey(s string, pos int, ctx keyContext) (*Key, error) {
+	k := &Key{}
+	keyPos := pos
+
+	// Key origin: [fingerprint/path]
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return nil, &ParseError{Pos: pos, Msg: "key origin is missing its closing ']'"}
+		}
+		origin, err := parseOrigin(s[1:end], pos+1)
+		if err != nil {
+			return nil, err
+		}
+		k.Origin = origin
+		s = s[end+1:]
+		keyPos = pos + end + 1
+	}
+	if strings.ContainsAny(s, "[]") {
+		return nil, &ParseError{Pos: keyPos, Msg: "unexpected '[' or ']' in key"}
+	}
+	if s == "" {
+		return nil, &ParseError{Pos: keyPos, Msg: "missing key"}
+	}
+
+	parts := strings.Split(s, "/")
+	material := parts[0]
+	k.Encoded = material
+
+	// Hex public keys
+	if raw, err := hex.DecodeString(material); err == nil {
+		if len(parts) > 1 {
+			return nil, &ParseError{Pos: keyPos, Msg: "derivation path is only allowed after an extended key"}
+		}
+		switch {
+		case len(raw) == 32:
+			if ctx != keyTaproot {
+				return nil, &ParseError{Pos: keyPos, Msg: "x-only public keys are only allowed inside tr()"}
+			}
+			if err := checkXOnlyPubKey(raw); err != nil {
+				return nil, &ParseError{Pos: keyPos, Msg: err.Error()}
+			}
+			k.Kind = KeyXOnlyHex
+		case len(raw) == 65 && (ctx == keyWitness || ctx == keyTaproot):
+			return nil, &ParseError{Pos: keyPos, Msg: "uncompressed public keys are not allowed in segwit scripts"}
+		default:
+			if err := checkPubKey(raw); err != nil {
+				return nil, &ParseError{Pos: keyPos, Msg: err.Error()}
+			}
+			k.Kind = KeyPublicHex
+		}
+		k.PubKey = raw
+		return k, nil
+	}
+
+	// Extended keys
+	if len(material) > 4 && (strings.HasPrefix(material, "xpub") || strings.HasPrefix(material, "xprv") ||
+		strings.HasPrefix(material, "tpub") || strings.HasPrefix(material, "tprv")) {
+		ext, err := ParseExtendedKey(material)
+		if err != nil {
+			return nil, &ParseError{Pos: keyPos, Msg: fmt.Sprintf("invalid extended key: %v", err)}
+		}
+		k.Extended = ext
+		k.Kind = KeyExtendedPublic
+		if ext.IsPrivate() {
+			k.Kind = KeyExtendedPrivate
+		}
+
+		offset := keyPos + len(material) + 1
+		steps := parts[1:]
+		if n := len(steps); n > 0 {
+			switch steps[n-1] {
+			case "*":
+				k.Wildcard = WildcardUnhardened
+				steps = steps[:n-1]
+			case "*'", "*h":
+				k.Wildcard = WildcardHardened
+				steps = steps[:n-1]
+			}
+		}
+		path, err := parsePath(steps, offset)
+		if err != nil {
+			return nil, err
+		}
+		k.Path = path
+		return k, nil
+	}
+
+	// WIF private keys
+	if len(parts) > 1 {
+		return nil, &ParseError{Pos: keyPos, Msg: "derivation path is only allowed after an extended key"}
+	}
+	payload, err := decodeBase58Check(material)
+	if err != nil {
+		return nil, &ParseError{Pos: keyPos, Msg: fmt.Sprintf("key %q is not hex, an extended key or WIF: %v", material, err)}
+	}
+	if (payload[0] != 0x80 && payload[0] != 0xef) || (len(payload) != 33 && len(payload) != 34) {
+		return nil, &ParseError{Pos: keyPos, Msg: fmt.Sprintf("key %q is not a valid WIF private key", material)}
+	}
+	if len(payload) == 34 && payload[33] != 0x01 {
+		return nil, &ParseError{Pos: keyPos, Msg: "WIF private key has an invalid compression flag"}
+	}
+	if len(payload) == 33 && (ctx == keyWitness || ctx == keyTaproot) {
+		return nil, &ParseError{Pos: keyPos, Msg: "uncompressed private keys are not allowed in segwit scripts"}
+	}
+	if err := checkPrivKey(payload[1:33]); err != nil {
+		return nil, &ParseError{Pos: keyPos, Msg: err.Error()}
+	}
+	k.Kind = KeyWIF
+	return k, nil
+}
+
+// parseOrigin parses the inside of a [fingerprint/path] key origin
+func parseOrigin(s string, pos int) (*KeyOrigin, error) {
+	parts := strings.Split(s, "/")
+	if len(parts[0]) != 8 {
+		return nil, &ParseError{Pos: pos, Msg: fmt.Sprintf("fingerprint %q is not 8 hex characters", parts[0])}
+	}
+	fp, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return nil, &ParseError{Pos: pos, Msg: fmt.Sprintf("fingerprint %q is not hex", parts[0])}
+	}
+	origin := &KeyOrigin{}
+	copy(origin.Fingerprint[:], fp)
+	path, err := parsePath(parts[1:], pos+len(parts[0])+1)
+	if err != nil {
+		return nil, err
+	}
+	origin.Path = path
+	return origin, nil
+}
+
+// parsePath parses BIP32 path steps such as 84', 0h or 5; pos is the offset of the first step
+func parsePath(steps []string, pos int) (Path, error) {
+	path := make(Path, 0, len(steps))
+	for _, step := range steps {
+		raw := step
+		var hardened bool
+		if strings.HasSuffix(step, "'") || strings.HasSuffix(step, "h") {
+			hardened = true
+			step = step[:len(step)-1]
+		}
+		if step == "*" {
+			return nil, &ParseError{Pos: pos, Msg: "wildcard is only allowed as the last path step"}
+		}
+		n, err := strconv.ParseUint(step, 10, 32)
+		if err != nil || step == "" || step[0] == '+' {
+			return nil, &ParseError{Pos: pos, Msg: fmt.Sprintf("invalid path step %q", raw)}
+		}
+		if uint32(n) >= hardenedKeyStart {
+			return nil, &ParseError{Pos: pos, Msg: fmt.Sprintf("path step %q is out of range", raw)}
+		}
+		if hardened {
+			n += uint64(hardenedKeyStart)
+		}
+		path = append(path, uint32(n))
+		pos += len(raw) + 1
+	}
+	return path, nil
+}
+
+// secp256k1 curve parameters
+var (
+	curveP, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	curveN, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	curveB    = big.NewInt(7)
+)
+
+// curveY2 returns x^3 + 7 mod p
+func curveY2(x *big.Int) *big.Int {
+	y2 := new(big.Int).Exp(x, big.NewInt(3), curveP)
+	y2.Add(y2, curveB)
+	return y2.Mod(y2, curveP)
+}
+
+// checkPubKey verifies that raw is a valid compressed or uncompressed secp256k1 point
+func checkPubKey(raw []byte) error {
+	switch {
+	case len(raw) == 33 && (raw[0] == 0x02 || raw[0] == 0x03):
+		return checkXOnlyPubKey(raw[1:])
+	case len(raw) == 65 && raw[0] == 0x04:
+		x := new(big.Int).SetBytes(raw[1:33])
+		y := new(big.Int).SetBytes(raw[33:65])
+		if x.Cmp(curveP) >= 0 || y.Cmp(curveP) >= 0 {
+			return errors.New("public key coordinate out of range")
+		}
+		if new(big.Int).Exp(y, big.NewInt(2), curveP).Cmp(curveY2(x)) != 0 {
+			return errors.New("public key is not on the secp256k1 curve")
+		}
+		return nil
+	default:
+		return fmt.Errorf("public key has invalid length %d or prefix", len(raw))
+	}
+}
+
+// checkXOnlyPubKey verifies that raw is the x coordinate of a secp256k1 point
+func checkXOnlyPubKey(raw []byte) error {
+	x := new(big.Int).SetBytes(raw)
+	if x.Cmp(curveP) >= 0 {
+		return errors.New("public key coordinate out of range")
+	}
+	if new(big.Int).ModSqrt(curveY2(x), curveP) == nil {
+		return errors.New("public key is not on the secp256k1 curve")
+	}
+	return nil
+}
+
+// checkPrivKey verifies that raw is a valid secp256k1 private key scalar
+func checkPrivKey(raw []byte) error {
+	d := new(big.Int).SetBytes(raw)
+	if d.Sign() == 0 || d.Cmp(curveN) >= 0 {
+		return errors.New("private key is out of range")
+	}
+	return nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// decodeBase58Check decodes a base58 string and verifies its 4-byte double-SHA256 checksum
+func decodeBase58Check(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("empty base58 string")
+	}
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(base58Alphabet, s[i])
+		if v < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", s[i])
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(v)))
+	}
+	decoded := n.Bytes()
+	// Leading '1' characters encode leading zero bytes
+	for i := 0; i < len(s) && s[i] == '1'; i++ {
+		decoded = append([]byte{0}, decoded...)
+	}
+	if len(decoded) < 5 {
+		return nil, errors.New("base58 string too short")
+	}
+	payload, sum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], sum) {
+		return nil, errors.New("base58 checksum mismatch")
+	}
+	return payload, nil
+}
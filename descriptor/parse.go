@@ -0,0 +1,312 @@
+package descriptor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// scriptContext is where a script expression appears, which limits the allowed functions
+type scriptContext int
+
+const (
+	ctxTop scriptContext = iota
+	ctxP2SH
+	ctxP2WSH
+	ctxP2TR
+)
+
+// keyContext limits which key encodings are allowed
+type keyContext int
+
+const (
+	keyLegacy keyContext = iota
+	keyWitness
+	keyTaproot
+)
+
+// Multisig key limits per context, matching Bitcoin Core
+const (
+	maxBareMultisigKeys  = 3
+	maxP2SHMultisigKeys  = 15
+	maxP2WSHMultisigKeys = 20
+	maxMultiAKeys        = 999
+)
+
+// Parse parses a descriptor, verifying its checksum if one is present
+func Parse(s string) (*Descriptor, error) {
+	body, sum := SplitChecksum(s)
+	if len(body) != len(s) {
+		if err := verifyChecksum(body, sum); err != nil {
+			return nil, err
+		}
+	} else if _, err := Checksum(body); err != nil {
+		// Reject characters outside the descriptor charset up front
+		return nil, err
+	}
+
+	p := &parser{s: body}
+	d, err := p.parseScript(ctxTop)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(body) {
+		return nil, p.errorf("unexpected %q after descriptor", body[p.pos:])
+	}
+	return d, nil
+}
+
+// ParseWithChecksum parses a descriptor and requires it to carry a valid checksum
+func ParseWithChecksum(s string) (*Descriptor, error) {
+	if err := VerifyChecksum(s); err != nil {
+		return nil, err
+	}
+	return Parse(s)
+}
+
+// parser is a recursive descent parser over a descriptor body
+type parser struct {
+	s   string
+	pos int
+}
+
+// errorf returns a ParseError at the current position
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// peek returns the next byte or 0 at the end of input
+func (p *parser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+// expect consumes c or fails
+func (p *parser) expect(c byte) error {
+	if p.peek() != c {
+		if p.pos >= len(p.s) {
+			return p.errorf("expected %q but reached end of descriptor", c)
+		}
+		return p.errorf("expected %q but found %q", c, p.s[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// readName reads a script function name
+func (p *parser) readName() string {
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] >= 'a' && p.s[p.pos] <= 'z' || p.s[p.pos] == '_') {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// readArg reads an argument up to the next ',', ')' or '}' and returns it with its offset
+func (p *parser) readArg() (string, int) {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(",)}", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	return p.s[start:p.pos], start
+}
+
+// parseKeyArg reads and parses a key argument
+func (p *parser) parseKeyArg(ctx keyContext) (*Key, error) {
+	arg, pos := p.readArg()
+	return parseKey(arg, pos, ctx)
+}
+
+// parseScript parses a script expression valid in ctx
+func (p *parser) parseScript(ctx scriptContext) (*Descriptor, error) {
+	namePos := p.pos
+	name := p.readName()
+	if name == "" {
+		return nil, p.errorf("expected a script function")
+	}
+	typ := ScriptType(name)
+	if err := checkContext(typ, ctx); err != nil {
+		return nil, &ParseError{Pos: namePos, Msg: err.Error()}
+	}
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	keyCtx := keyLegacy
+	switch ctx {
+	case ctxP2WSH:
+		keyCtx = keyWitness
+	case ctxP2TR:
+		keyCtx = keyTaproot
+	}
+
+	d := &Descriptor{Type: typ}
+	switch typ {
+	case TypePK, TypePKH, TypeCombo:
+		key, err := p.parseKeyArg(keyCtx)
+		if err != nil {
+			return nil, err
+		}
+		d.Keys = []*Key{key}
+
+	case TypeWPKH:
+		key, err := p.parseKeyArg(keyWitness)
+		if err != nil {
+			return nil, err
+		}
+		d.Keys = []*Key{key}
+
+	case TypeSH, TypeWSH:
+		subCtx := ctxP2SH
+		if typ == TypeWSH {
+			subCtx = ctxP2WSH
+		}
+		sub, err := p.parseScript(subCtx)
+		if err != nil {
+			return nil, err
+		}
+		d.Sub = sub
+
+	case TypeMulti, TypeSortedMulti, TypeMultiA, TypeSortedMultiA:
+		if err := p.parseMulti(d, ctx, keyCtx); err != nil {
+			return nil, err
+		}
+
+	case TypeTR:
+		key, err := p.parseKeyArg(keyTaproot)
+		if err != nil {
+			return nil, err
+		}
+		d.Keys = []*Key{key}
+		if p.peek() == ',' {
+			p.pos++
+			tree, err := p.parseTree(1)
+			if err != nil {
+				return nil, err
+			}
+			d.Tree = tree
+		}
+
+	case TypeAddr:
+		arg, pos := p.readArg()
+		if err := validateAddress(arg); err != nil {
+			return nil, &ParseError{Pos: pos, Msg: fmt.Sprintf("invalid address %q: %v", arg, err)}
+		}
+		d.Address = arg
+
+	case TypeRaw:
+		arg, pos := p.readArg()
+		script, err := hex.DecodeString(arg)
+		if err != nil || len(script) == 0 {
+			return nil, &ParseError{Pos: pos, Msg: fmt.Sprintf("raw script %q is not non-empty hex", arg)}
+		}
+		d.Script = script
+	}
+
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// parseMulti parses the threshold and keys of a multisig expression into d
+func (p *parser) parseMulti(d *Descriptor, ctx scriptContext, keyCtx keyContext) error {
+	arg, pos := p.readArg()
+	k, err := strconv.Atoi(arg)
+	if err != nil || arg == "" || arg[0] == '+' || arg[0] == '-' {
+		return &ParseError{Pos: pos, Msg: fmt.Sprintf("multisig threshold %q is not a number", arg)}
+	}
+	for p.peek() == ',' {
+		p.pos++
+		key, err := p.parseKeyArg(keyCtx)
+		if err != nil {
+			return err
+		}
+		d.Keys = append(d.Keys, key)
+	}
+
+	n := len(d.Keys)
+	if n == 0 {
+		return &ParseError{Pos: pos, Msg: "multisig needs at least one key"}
+	}
+	if k < 1 || k > n {
+		return &ParseError{Pos: pos, Msg: fmt.Sprintf("multisig threshold %d must be between 1 and %d keys", k, n)}
+	}
+
+	limit := maxMultiAKeys
+	switch ctx {
+	case ctxTop:
+		limit = maxBareMultisigKeys
+	case ctxP2SH:
+		limit = maxP2SHMultisigKeys
+	case ctxP2WSH:
+		limit = maxP2WSHMultisigKeys
+	}
+	if n > limit {
+		return &ParseError{Pos: pos, Msg: fmt.Sprintf("%s has %d keys, at most %d are allowed here", d.Type, n, limit)}
+	}
+
+	d.Threshold = k
+	return nil
+}
+
+// parseTree parses a taproot script tree at the given depth
+func (p *parser) parseTree(depth int) (*TapTree, error) {
+	if p.peek() != '{' {
+		leaf, err := p.parseScript(ctxP2TR)
+		if err != nil {
+			return nil, err
+		}
+		return &TapTree{Leaf: leaf}, nil
+	}
+
+	if depth > maxTapTreeDepth {
+		return nil, p.errorf("taproot script tree is deeper than %d", maxTapTreeDepth)
+	}
+	p.pos++
+	left, err := p.parseTree(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(','); err != nil {
+		return nil, err
+	}
+	right, err := p.parseTree(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+	return &TapTree{Left: left, Right: right}, nil
+}
+
+// checkContext reports whether typ may appear in ctx
+func checkContext(typ ScriptType, ctx scriptContext) error {
+	switch typ {
+	case TypePK, TypePKH:
+		return nil
+	case TypeSH, TypeTR, TypeCombo, TypeAddr, TypeRaw:
+		if ctx != ctxTop {
+			return fmt.Errorf("%s() is only allowed at the top level", typ)
+		}
+	case TypeWPKH, TypeWSH:
+		if ctx != ctxTop && ctx != ctxP2SH {
+			return fmt.Errorf("%s() is only allowed at the top level or inside sh()", typ)
+		}
+	case TypeMulti, TypeSortedMulti:
+		if ctx == ctxP2TR {
+			return fmt.Errorf("%s() is not allowed inside tr(), use %s_a()", typ, typ)
+		}
+	case TypeMultiA, TypeSortedMultiA:
+		if ctx != ctxP2TR {
+			return fmt.Errorf("%s() is only allowed inside tr()", typ)
+		}
+	default:
+		return fmt.Errorf("unknown script function %q", typ)
+	}
+	return nil
+}
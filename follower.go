@@ -0,0 +1,273 @@
+package btcrpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BlockEventType identifies the kind of change reported by a BlockFollower
+type BlockEventType int
+
+const (
+	// BlockConnected is emitted when a block is added to the followed chain
+	BlockConnected BlockEventType = iota
+	// BlockDisconnected is emitted when a block is removed from the followed chain by a reorg
+	BlockDisconnected
+)
+
+// String returns a readable name for the event type
+func (t BlockEventType) String() string {
+	switch t {
+	case BlockConnected:
+		return "connected"
+	case BlockDisconnected:
+		return "disconnected"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+// BlockEvent is a single change of the followed chain
+type BlockEvent struct {
+	Type   BlockEventType
+	Hash   string
+	Height int
+	Header *GetBlockHeaderResponse
+}
+
+// BlockRef identifies a block by hash and height
+type BlockRef struct {
+	Hash   string `json:"hash"`
+	Height int    `json:"height"`
+}
+
+// CheckpointStore persists the last block handled by a follower between runs
+// LoadCheckpoint returns nil when no checkpoint has been saved yet
+type CheckpointStore interface {
+	LoadCheckpoint(name string) (*BlockRef, error)
+	SaveCheckpoint(name string, tip BlockRef) error
+}
+
+// MemoryCheckpointStore is an in-memory CheckpointStore, mostly useful for tests and short-lived processes
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]BlockRef
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory checkpoint store
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]BlockRef)}
+}
+
+// LoadCheckpoint returns the saved checkpoint for name
+func (s *MemoryCheckpointStore) LoadCheckpoint(name string) (*BlockRef, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tip, ok := s.checkpoints[name]
+	if !ok {
+		return nil, nil
+	}
+	return &tip, nil
+}
+
+// SaveCheckpoint stores the checkpoint for name
+func (s *MemoryCheckpointStore) SaveCheckpoint(name string, tip BlockRef) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[name] = tip
+	return nil
+}
+
+// BlockFollower follows the best chain block by block and reports reorgs
+//
+// The follower remembers the last block it handled (its tip). A new block is only
+// connected if its previousblockhash is that tip. Otherwise, or when the node reports
+// the tip as no longer on the main chain (confirmations -1), the tip is disconnected
+// and replaced by its parent until the fork point is reached, then the new branch is
+// connected. The tip is saved to the checkpoint store after every event, so a restart
+// resumes exactly where it stopped, including rewinding a reorg that happened meanwhile.
+type BlockFollower struct {
+	client *Client
+	name   string
+	store  CheckpointStore
+	start  BlockRef
+
+	tip    *BlockRef
+	loaded bool
+}
+
+// NewBlockFollower creates a follower whose progress is saved in store under name
+// Without a saved checkpoint, following starts at start: at start.Hash if set, otherwise at start.Height.
+func NewBlockFollower(client *Client, name string, store CheckpointStore, start BlockRef) *BlockFollower {
+	return &BlockFollower{
+		client: client,
+		name:   name,
+		store:  store,
+		start:  start,
+	}
+}
+
+// Tip returns the last block handled, or nil if none has been handled yet
+func (f *BlockFollower) Tip() *BlockRef {
+	if f.tip == nil {
+		return nil
+	}
+	tip := *f.tip
+	return &tip
+}
+
+// Sync connects every block up to the current best block, passing events to handle in order
+// The checkpoint is only advanced once handle has accepted an event.
+func (f *BlockFollower) Sync(handle func(BlockEvent) error) error {
+	next, err := f.nextHeight()
+	if err != nil {
+		return err
+	}
+
+	if f.tip != nil {
+		if err := f.rewind(handle); err != nil {
+			return err
+		}
+		next = f.tip.Height + 1
+	}
+
+	best, err := f.client.GetBlockCount()
+	if err != nil {
+		return err
+	}
+
+	for next <= best {
+		hash, err := f.client.GetBlockHash(next)
+		if err != nil {
+			return err
+		}
+		header, err := f.client.GetBlockHeader(hash)
+		if err != nil {
+			return err
+		}
+
+		if f.tip != nil && header.PreviousBlockHash != f.tip.Hash {
+			// Either our tip was reorged out or the chain changed between the two calls
+			if err := f.rewind(handle); err != nil {
+				return err
+			}
+			next = f.tip.Height + 1
+			continue
+		}
+
+		if err := handle(BlockEvent{Type: BlockConnected, Hash: header.Hash, Height: header.Height, Header: header}); err != nil {
+			return err
+		}
+		if err := f.setTip(&BlockRef{Hash: header.Hash, Height: header.Height}); err != nil {
+			return err
+		}
+		next = header.Height + 1
+	}
+
+	return nil
+}
+
+// Run calls Sync every interval until ctx is cancelled or a pass fails
+func (f *BlockFollower) Run(ctx context.Context, interval time.Duration, handle func(BlockEvent) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.Sync(handle); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Follow is like Run but long-polls with waitforblockheight instead of sleeping between passes,
+// so new blocks are handled as soon as the node has them. maxWait bounds each wait, which
+// also bounds how late a reorg that does not raise the height is noticed (default 1 minute).
+func (f *BlockFollower) Follow(ctx context.Context, maxWait time.Duration, handle func(BlockEvent) error) error {
+	if maxWait <= 0 {
+		maxWait = time.Minute
+	}
+
+	for {
+		if err := f.Sync(handle); err != nil {
+			return err
+		}
+
+		next, err := f.nextHeight()
+		if err != nil {
+			return err
+		}
+		// waitforblockheight returns at once if the height was reached since Sync, so no block is missed
+		if _, err := f.client.WaitForBlockHeight(ctx, next, maxWait); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+	}
+}
+
+// nextHeight loads the checkpoint on first use and returns the height to connect next
+func (f *BlockFollower) nextHeight() (int, error) {
+	if !f.loaded {
+		tip, err := f.store.LoadCheckpoint(f.name)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load checkpoint: %v", err)
+		}
+		f.tip = tip
+		f.loaded = true
+	}
+	if f.tip != nil {
+		return f.tip.Height + 1, nil
+	}
+
+	if f.start.Hash != "" {
+		header, err := f.client.GetBlockHeader(f.start.Hash)
+		if err != nil {
+			return 0, err
+		}
+		f.start = BlockRef{Height: header.Height}
+	}
+	return f.start.Height, nil
+}
+
+// rewind disconnects tip blocks until the tip is on the main chain again
+func (f *BlockFollower) rewind(handle func(BlockEvent) error) error {
+	for f.tip != nil {
+		header, err := f.client.GetBlockHeader(f.tip.Hash)
+		if err != nil {
+			return err
+		}
+		if header.Confirmations >= 0 {
+			return nil
+		}
+
+		if err := handle(BlockEvent{Type: BlockDisconnected, Hash: header.Hash, Height: header.Height, Header: header}); err != nil {
+			return err
+		}
+
+		if header.PreviousBlockHash == "" {
+			return fmt.Errorf("genesis block %s is not on the main chain", header.Hash)
+		}
+		if err := f.setTip(&BlockRef{Hash: header.PreviousBlockHash, Height: header.Height - 1}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setTip records the new tip and saves it as the checkpoint
+func (f *BlockFollower) setTip(tip *BlockRef) error {
+	f.tip = tip
+	if err := f.store.SaveCheckpoint(f.name, *tip); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+// Package gcs decodes and queries BIP158 Golomb-coded set filters, as returned by
+// getblockfilter, and computes the filter header chain, so a light client can tell
+// whether a block touches its scripts without downloading the block.
+package gcs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// Parameters of the BIP158 basic filter
+const (
+	BasicP = 19
+	BasicM = 784931
+)
+
+// Filter is a Golomb-coded set of N items
+type Filter struct {
+	N uint32
+	P uint8
+	M uint64
+
+	raw  []byte // serialized filter, N followed by the Golomb-Rice coded deltas
+	data []byte // the coded deltas
+}
+
+// NewBasicFilter parses a serialized BIP158 basic filter
+func NewBasicFilter(filter []byte) (*Filter, error) {
+	return NewFilter(filter, BasicP, BasicM)
+}
+
+// NewBasicFilterHex parses a hex encoded BIP158 basic filter, as returned by getblockfilter
+func NewBasicFilterHex(s string) (*Filter, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("gcs: invalid hex: %w", err)
+	}
+	return NewBasicFilter(b)
+}
+
+// NewFilter parses a serialized filter with the given parameters
+func NewFilter(filter []byte, p uint8, m uint64) (*Filter, error) {
+	n, size, err := readCompactSize(filter)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0xffffffff {
+		return nil, fmt.Errorf("gcs: filter claims %d items", n)
+	}
+	if p > 32 {
+		return nil, fmt.Errorf("gcs: invalid parameter P %d", p)
+	}
+	// Every item takes at least P+1 bits, the unary terminator and the remainder
+	if n > uint64(len(filter)-size)*8/(uint64(p)+1) {
+		return nil, fmt.Errorf("gcs: filter claims %d items in %d bytes", n, len(filter)-size)
+	}
+	return &Filter{
+		N:    uint32(n),
+		P:    p,
+		M:    m,
+		raw:  filter,
+		data: filter[size:],
+	}, nil
+}
+
+// Bytes returns the serialized filter
+func (f *Filter) Bytes() []byte {
+	return f.raw
+}
+
+// Hash returns the double SHA256 of the serialized filter, in internal byte order
+func (f *Filter) Hash() [32]byte {
+	return doubleSHA256(f.raw)
+}
+
+// Header returns the filter header chained to prevHeader, in internal byte order
+// The header of the genesis block's filter is chained to the zero hash.
+func (f *Filter) Header(prevHeader [32]byte) [32]byte {
+	hash := f.Hash()
+	var buf [64]byte
+	copy(buf[:32], hash[:])
+	copy(buf[32:], prevHeader[:])
+	return doubleSHA256(buf[:])
+}
+
+// Match reports whether item is probably in the set
+// False positives happen at a rate of about 1/M, false negatives never.
+func (f *Filter) Match(key [16]byte, item []byte) (bool, error) {
+	return f.MatchAny(key, [][]byte{item})
+}
+
+// MatchAny reports whether any of items is probably in the set
+func (f *Filter) MatchAny(key [16]byte, items [][]byte) (bool, error) {
+	if f.N == 0 || len(items) == 0 {
+		return false, nil
+	}
+
+	// Hash the query into the same range and walk both sorted lists in one pass
+	bound := uint64(f.N) * f.M
+	targets := make([]uint64, 0, len(items))
+	for _, item := range items {
+		targets = append(targets, hashToRange(key, item, bound))
+	}
+	slices.Sort(targets)
+
+	r := bitReader{data: f.data}
+	var value uint64
+	next := 0
+	for i := uint32(0); i < f.N; i++ {
+		delta, err := r.readGolombRice(f.P)
+		if err != nil {
+			return false, err
+		}
+		value += delta
+
+		for next < len(targets) && targets[next] < value {
+			next++
+		}
+		if next == len(targets) {
+			return false, nil
+		}
+		if targets[next] == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Values decodes every item hash in the set, in ascending order
+func (f *Filter) Values() ([]uint64, error) {
+	values := make([]uint64, 0, f.N)
+	r := bitReader{data: f.data}
+	var value uint64
+	for i := uint32(0); i < f.N; i++ {
+		delta, err := r.readGolombRice(f.P)
+		if err != nil {
+			return nil, err
+		}
+		value += delta
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Key returns the SipHash key of a block's basic filter, the first 16 bytes of the block hash in internal byte order
+func Key(blockHash [32]byte) [16]byte {
+	var key [16]byte
+	copy(key[:], blockHash[:16])
+	return key
+}
+
+// KeyFromHex returns the filter key of the block whose hash is given in the usual reversed hex form
+func KeyFromHex(blockHash string) ([16]byte, error) {
+	hash, err := ParseHash(blockHash)
+	if err != nil {
+		return [16]byte{}, err
+	}
+	return Key(hash), nil
+}
+
+// ParseHash decodes a hash in reversed byte order, as used by RPC, into internal byte order
+func ParseHash(s string) ([32]byte, error) {
+	var h [32]byte
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return h, fmt.Errorf("gcs: invalid hash: %w", err)
+	}
+	if len(b) != 32 {
+		return h, fmt.Errorf("gcs: hash is %d bytes, expected 32", len(b))
+	}
+	for i := range b {
+		h[31-i] = b[i]
+	}
+	return h, nil
+}
+
+// HashToString renders a hash in the reversed byte order used by RPC
+func HashToString(h [32]byte) string {
+	for i, j := 0, len(h)-1; i < j; i, j = i+1, j-1 {
+		h[i], h[j] = h[j], h[i]
+	}
+	return hex.EncodeToString(h[:])
+}
+
+// hashToRange maps item uniformly onto [0, bound) with SipHash-2-4 and a 64x64 bit multiply
+func hashToRange(key [16]byte, item []byte, bound uint64) uint64 {
+	hi, _ := bits.Mul64(sipHash24(key, item), bound)
+	return hi
+}
+
+// bitReader reads a big-endian bit stream
+type bitReader struct {
+	data []byte
+	pos  uint64 // in bits
+}
+
+// readBit returns the next bit
+func (r *bitReader) readBit() (uint64, error) {
+	if r.pos >= uint64(len(r.data))*8 {
+		return 0, errors.New("gcs: filter data ends early")
+	}
+	bit := uint64(r.data[r.pos/8]>>(7-r.pos%8)) & 1
+	r.pos++
+	return bit, nil
+}
+
+// readBits returns the next n bits as an integer, most significant bit first
+func (r *bitReader) readBits(n uint8) (uint64, error) {
+	var v uint64
+	for range n {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<1 | bit
+	}
+	return v, nil
+}
+
+// readGolombRice decodes one value: a unary quotient terminated by 0, then a p bit remainder
+func (r *bitReader) readGolombRice(p uint8) (uint64, error) {
+	var quotient uint64
+	for {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		if bit == 0 {
+			break
+		}
+		quotient++
+	}
+	remainder, err := r.readBits(p)
+	if err != nil {
+		return 0, err
+	}
+	return quotient<<p | remainder, nil
+}
+
+// sipHash24 is SipHash-2-4 with a 128 bit key, as used by BIP158
+func sipHash24(key [16]byte, msg []byte) uint64 {
+	k0 := binary.LittleEndian.Uint64(key[0:8])
+	k1 := binary.LittleEndian.Uint64(key[8:16])
+
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = bits.RotateLeft64(v1, 13)
+		v1 ^= v0
+		v0 = bits.RotateLeft64(v0, 32)
+		v2 += v3
+		v3 = bits.RotateLeft64(v3, 16)
+		v3 ^= v2
+		v0 += v3
+		v3 = bits.RotateLeft64(v3, 21)
+		v3 ^= v0
+		v2 += v1
+		v1 = bits.RotateLeft64(v1, 17)
+		v1 ^= v2
+		v2 = bits.RotateLeft64(v2, 32)
+	}
+
+	n := len(msg)
+	for len(msg) >= 8 {
+		m := binary.LittleEndian.Uint64(msg)
+		v3 ^= m
+		round()
+		round()
+		v0 ^= m
+		msg = msg[8:]
+	}
+
+	// The last block holds the remaining bytes and the message length in its top byte
+	var last [8]byte
+	copy(last[:], msg)
+	last[7] = byte(n)
+	m := binary.LittleEndian.Uint64(last[:])
+	v3 ^= m
+	round()
+	round()
+	v0 ^= m
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+// doubleSHA256 returns SHA256(SHA256(b))
+func doubleSHA256(b []byte) [32]byte {
+	first := sha256.Sum256(b)
+	return sha256.Sum256(first[:])
+}
+
+// readCompactSize decodes the bitcoin variable length integer at the start of b and returns it with its size
+func readCompactSize(b []byte) (uint64, int, error) {
+	if len(b) == 0 {
+		return 0, 0, errors.New("gcs: empty filter")
+	}
+	r := bytes.NewReader(b[1:])
+	switch b[0] {
+	case 0xfd:
+		var v uint16
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return 0, 0, errors.New("gcs: truncated item count")
+		}
+		return uint64(v), 3, nil
+	case 0xfe:
+		var v uint32
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return 0, 0, errors.New("gcs: truncated item count")
+		}
+		return uint64(v), 5, nil
+	case 0xff:
+		var v uint64
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return 0, 0, errors.New("gcs: truncated item count")
+		}
+		return v, 9, nil
+	default:
+		return uint64(b[0]), 1, nil
+	}
+}
@@ -0,0 +1,370 @@
+// Package merkle parses block headers and merkle blocks (the proofs returned by
+// gettxoutproof) and verifies them offline, so inclusion proofs can be stored and
+// checked later without a node.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// HeaderSize is the size of a serialized block header
+const HeaderSize = 80
+
+// maxTransactions is the most transactions a block can hold, MAX_BLOCK_WEIGHT / MIN_TRANSACTION_WEIGHT
+const maxTransactions = 4_000_000 / 240
+
+// Header is a block header
+type Header struct {
+	Version    int32
+	PrevBlock  [32]byte // internal byte order
+	MerkleRoot [32]byte // internal byte order
+	Time       uint32
+	Bits       uint32
+	Nonce      uint32
+}
+
+// ParseHeader decodes an 80 byte block header
+func ParseHeader(b []byte) (*Header, error) {
+	if len(b) != HeaderSize {
+		return nil, fmt.Errorf("merkle: header is %d bytes, expected %d", len(b), HeaderSize)
+	}
+	h := &Header{
+		Version: int32(binary.LittleEndian.Uint32(b[0:4])),
+		Time:    binary.LittleEndian.Uint32(b[68:72]),
+		Bits:    binary.LittleEndian.Uint32(b[72:76]),
+		Nonce:   binary.LittleEndian.Uint32(b[76:80]),
+	}
+	copy(h.PrevBlock[:], b[4:36])
+	copy(h.MerkleRoot[:], b[36:68])
+	return h, nil
+}
+
+// Serialize encodes the header
+func (h *Header) Serialize() []byte {
+	b := make([]byte, HeaderSize)
+	binary.LittleEndian.PutUint32(b[0:4], uint32(h.Version))
+	copy(b[4:36], h.PrevBlock[:])
+	copy(b[36:68], h.MerkleRoot[:])
+	binary.LittleEndian.PutUint32(b[68:72], h.Time)
+	binary.LittleEndian.PutUint32(b[72:76], h.Bits)
+	binary.LittleEndian.PutUint32(b[76:80], h.Nonce)
+	return b
+}
+
+// Hash returns the block hash in internal byte order
+func (h *Header) Hash() [32]byte {
+	return doubleSHA256(h.Serialize())
+}
+
+// BlockHash returns the block hash as a hex string in the usual reversed byte order
+func (h *Header) BlockHash() string {
+	return HashToString(h.Hash())
+}
+
+// PrevBlockHash returns the previous block hash as a hex string in the usual reversed byte order
+func (h *Header) PrevBlockHash() string {
+	return HashToString(h.PrevBlock)
+}
+
+// MerkleRootHash returns the merkle root as a hex string in the usual reversed byte order
+func (h *Header) MerkleRootHash() string {
+	return HashToString(h.MerkleRoot)
+}
+
+// CheckProofOfWork verifies that the block hash meets the target encoded in Bits
+// It does not check that Bits is the right difficulty for the block's height.
+func (h *Header) CheckProofOfWork() error {
+	target, err := compactToTarget(h.Bits)
+	if err != nil {
+		return err
+	}
+
+	hash := h.Hash()
+	reversed := reverse(hash)
+	if new(big.Int).SetBytes(reversed[:]).Cmp(target) > 0 {
+		return fmt.Errorf("merkle: block hash %s is above the target of bits %08x", h.BlockHash(), h.Bits)
+	}
+	return nil
+}
+
+// MerkleBlock is a block header with a partial merkle tree proving the inclusion of some transactions
+type MerkleBlock struct {
+	Header       Header
+	Transactions uint32     // number of transactions in the block
+	Hashes       [][32]byte // tree hashes in depth-first order, internal byte order
+	Flags        []byte     // traversal bits, least significant bit first
+}
+
+// Parse decodes a serialized merkle block
+func Parse(b []byte) (*MerkleBlock, error) {
+	if len(b) < HeaderSize+4 {
+		return nil, errors.New("merkle: merkle block too short")
+	}
+	header, err := ParseHeader(b[:HeaderSize])
+	if err != nil {
+		return nil, err
+	}
+
+	r := bytes.NewReader(b[HeaderSize:])
+	m := &MerkleBlock{Header: *header}
+	if err := binary.Read(r, binary.LittleEndian, &m.Transactions); err != nil {
+		return nil, err
+	}
+
+	count, err := readCompactSize(r)
+	if err != nil {
+		return nil, fmt.Errorf("merkle: failed to read hash count: %w", err)
+	}
+	if count > uint64(r.Len())/32 {
+		return nil, fmt.Errorf("merkle: hash count %d exceeds the data", count)
+	}
+	m.Hashes = make([][32]byte, count)
+	for i := range m.Hashes {
+		if _, err := io.ReadFull(r, m.Hashes[i][:]); err != nil {
+			return nil, err
+		}
+	}
+
+	size, err := readCompactSize(r)
+	if err != nil {
+		return nil, fmt.Errorf("merkle: failed to read flag count: %w", err)
+	}
+	if size > uint64(r.Len()) {
+		return nil, fmt.Errorf("merkle: flag count %d exceeds the data", size)
+	}
+	m.Flags = make([]byte, size)
+	if _, err := io.ReadFull(r, m.Flags); err != nil {
+		return nil, err
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("merkle: %d trailing bytes after merkle block", r.Len())
+	}
+	return m, nil
+}
+
+// ParseHex decodes a hex encoded merkle block, as returned by gettxoutproof
+func ParseHex(s string) (*MerkleBlock, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("merkle: invalid hex: %w", err)
+	}
+	return Parse(b)
+}
+
+// Match is a transaction proven to be in the block
+type Match struct {
+	TxID  string // reversed byte order, as used by RPC
+	Index int    // position of the transaction in the block
+}
+
+// ExtractMatches walks the partial merkle tree and returns the merkle root it commits to
+// together with the matched transactions. The root still has to be compared with a trusted
+// header, see Verify.
+func (m *MerkleBlock) ExtractMatches() ([32]byte, []Match, error) {
+	var root [32]byte
+
+	// Same sanity checks as Bitcoin Core's CPartialMerkleTree::ExtractMatches
+	if m.Transactions == 0 {
+		return root, nil, errors.New("merkle: merkle block has no transactions")
+	}
+	if m.Transactions > maxTransactions {
+		return root, nil, fmt.Errorf("merkle: %d transactions exceed the block limit", m.Transactions)
+	}
+	if uint64(len(m.Hashes)) > uint64(m.Transactions) {
+		return root, nil, errors.New("merkle: more hashes than transactions")
+	}
+	if len(m.Flags)*8 < len(m.Hashes) {
+		return root, nil, errors.New("merkle: fewer flag bits than hashes")
+	}
+
+	height := 0
+	for m.treeWidth(height) > 1 {
+		height++
+	}
+
+	t := traversal{block: m}
+	root = t.walk(height, 0)
+	if t.err != nil {
+		return [32]byte{}, nil, t.err
+	}
+	// Every hash and every flag byte must have been used
+	if (t.bitsUsed+7)/8 != len(m.Flags) {
+		return [32]byte{}, nil, errors.New("merkle: unused flag bytes")
+	}
+	if t.hashesUsed != len(m.Hashes) {
+		return [32]byte{}, nil, errors.New("merkle: unused hashes")
+	}
+	return root, t.matches, nil
+}
+
+// Verify checks the partial merkle tree against the merkle root of the embedded header
+// and returns the matched transactions. The header itself must be trusted separately,
+// for example by checking BlockHash against a block known to be in the best chain.
+func (m *MerkleBlock) Verify() ([]Match, error) {
+	root, matches, err := m.ExtractMatches()
+	if err != nil {
+		return nil, err
+	}
+	if root != m.Header.MerkleRoot {
+		return nil, fmt.Errorf("merkle: computed root %s does not match header root %s", HashToString(root), m.Header.MerkleRootHash())
+	}
+	return matches, nil
+}
+
+// Contains verifies the merkle block and reports whether it proves txid
+func (m *MerkleBlock) Contains(txid string) (bool, error) {
+	matches, err := m.Verify()
+	if err != nil {
+		return false, err
+	}
+	for _, match := range matches {
+		if match.TxID == txid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// treeWidth returns the number of nodes at height, 0 being the leaves
+func (m *MerkleBlock) treeWidth(height int) uint64 {
+	return (uint64(m.Transactions) + (1 << height) - 1) >> height
+}
+
+// traversal is the state of a depth-first walk over a partial merkle tree
+type traversal struct {
+	block      *MerkleBlock
+	bitsUsed   int
+	hashesUsed int
+	matches    []Match
+	err        error
+}
+
+// walk returns the hash of the node at height and pos, recording matched leaves
+func (t *traversal) walk(height int, pos uint64) [32]byte {
+	if t.err != nil {
+		return [32]byte{}
+	}
+	if t.bitsUsed >= len(t.block.Flags)*8 {
+		t.err = errors.New("merkle: ran out of flag bits")
+		return [32]byte{}
+	}
+	parentOfMatch := t.block.Flags[t.bitsUsed/8]>>(t.bitsUsed%8)&1 == 1
+	t.bitsUsed++
+
+	if height == 0 || !parentOfMatch {
+		if t.hashesUsed >= len(t.block.Hashes) {
+			t.err = errors.New("merkle: ran out of hashes")
+			return [32]byte{}
+		}
+		hash := t.block.Hashes[t.hashesUsed]
+		t.hashesUsed++
+		if height == 0 && parentOfMatch {
+			t.matches = append(t.matches, Match{TxID: HashToString(hash), Index: int(pos)})
+		}
+		return hash
+	}
+
+	left := t.walk(height-1, pos*2)
+	right := left
+	if pos*2+1 < t.block.treeWidth(height-1) {
+		right = t.walk(height-1, pos*2+1)
+		// Identical siblings would allow the CVE-2012-2459 duplicate transaction trick
+		if t.err == nil && right == left {
+			t.err = errors.New("merkle: identical left and right hashes")
+		}
+	}
+
+	var pair [64]byte
+	copy(pair[:32], left[:])
+	copy(pair[32:], right[:])
+	return doubleSHA256(pair[:])
+}
+
+// HashToString renders a hash in the reversed byte order used by RPC and block explorers
+func HashToString(h [32]byte) string {
+	r := reverse(h)
+	return hex.EncodeToString(r[:])
+}
+
+// ParseHash decodes a hash in reversed byte order, as used by RPC, into internal byte order
+func ParseHash(s string) ([32]byte, error) {
+	var h [32]byte
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return h, fmt.Errorf("merkle: invalid hash: %w", err)
+	}
+	if len(b) != 32 {
+		return h, fmt.Errorf("merkle: hash is %d bytes, expected 32", len(b))
+	}
+	copy(h[:], b)
+	return reverse(h), nil
+}
+
+// compactToTarget expands the compact target representation used in Bits
+func compactToTarget(bits uint32) (*big.Int, error) {
+	exponent := uint(bits >> 24)
+	mantissa := int64(bits & 0x007fffff)
+	if bits&0x00800000 != 0 && mantissa != 0 {
+		return nil, fmt.Errorf("merkle: negative target in bits %08x", bits)
+	}
+
+	target := big.NewInt(mantissa)
+	if exponent <= 3 {
+		target.Rsh(target, 8*(3-exponent))
+	} else {
+		target.Lsh(target, 8*(exponent-3))
+	}
+	if target.Sign() == 0 {
+		return nil, fmt.Errorf("merkle: zero target in bits %08x", bits)
+	}
+	if target.BitLen() > 256 {
+		return nil, fmt.Errorf("merkle: target in bits %08x overflows", bits)
+	}
+	return target, nil
+}
+
+// doubleSHA256 returns SHA256(SHA256(b))
+func doubleSHA256(b []byte) [32]byte {
+	first := sha256.Sum256(b)
+	return sha256.Sum256(first[:])
+}
+
+// reverse returns h with its bytes in reverse order
+func reverse(h [32]byte) [32]byte {
+	for i, j := 0, len(h)-1; i < j; i, j = i+1, j-1 {
+		h[i], h[j] = h[j], h[i]
+	}
+	return h
+}
+
+// readCompactSize reads a bitcoin variable length integer
+func readCompactSize(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch prefix {
+	case 0xfd:
+		var v uint16
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), err
+	case 0xfe:
+		var v uint32
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), err
+	case 0xff:
+		var v uint64
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	default:
+		return uint64(prefix), nil
+	}
+}
@@ -0,0 +1,556 @@
+package btcrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koinvote/btcrpc/psbt"
+)
+
+// ErrSpendNotFound is returned by a MultisigSpendStore when no spend has the requested id
+var ErrSpendNotFound = errors.New("multisig spend not found")
+
+// MultisigSpendStatus is the stage a multisig spend has reached
+type MultisigSpendStatus string
+
+const (
+	// SpendPending means signatures are still being collected
+	SpendPending MultisigSpendStatus = "pending"
+	// SpendFinalized means the PSBT is complete and the network transaction has been extracted
+	SpendFinalized MultisigSpendStatus = "finalized"
+	// SpendBroadcast means the transaction has been accepted by the node
+	SpendBroadcast MultisigSpendStatus = "broadcast"
+)
+
+// Cosigner is one key holder of a multisig wallet
+// Cosigners are recognised in the PSBT by the master key fingerprint of their BIP32 derivations.
+type Cosigner struct {
+	Name        string `json:"name"`
+	Fingerprint string `json:"fingerprint"`           // master key fingerprint, 8 hex characters
+	WalletName  string `json:"wallet_name,omitempty"` // signer wallet on the node, if the key lives there
+}
+
+// MultisigSpend is the persisted state of a pending multisig spend
+type MultisigSpend struct {
+	ID          string              `json:"id"`
+	WatchWallet string              `json:"watch_wallet"`
+	Required    int                 `json:"required"`
+	Cosigners   []Cosigner          `json:"cosigners"`
+	Status      MultisigSpendStatus `json:"status"`
+	PSBT        string              `json:"psbt"`           // combined PSBT (base64)
+	Fee         float64             `json:"fee"`            // fee in BTC as reported when funding
+	Hex         string              `json:"hex,omitempty"`  // final network transaction once finalized
+	TxID        string              `json:"txid,omitempty"` // unsigned transaction id while pending, broadcast id afterwards
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// MultisigSpendStore persists multisig spends so they can be resumed after a restart
+// LoadSpend returns ErrSpendNotFound when id is unknown
+type MultisigSpendStore interface {
+	LoadSpend(id string) (*MultisigSpend, error)
+	SaveSpend(spend *MultisigSpend) error
+}
+
+// MemorySpendStore is an in-memory MultisigSpendStore, mostly useful for tests and short-lived processes
+type MemorySpendStore struct {
+	mu     sync.Mutex
+	spends map[string]MultisigSpend
+}
+
+// NewMemorySpendStore creates an empty in-memory spend store
+func NewMemorySpendStore() *MemorySpendStore {
+	return &MemorySpendStore{spends: make(map[string]MultisigSpend)}
+}
+
+// LoadSpend returns a copy of the saved spend
+func (s *MemorySpendStore) LoadSpend(id string) (*MultisigSpend, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	spend, ok := s.spends[id]
+	if !ok {
+		return nil, ErrSpendNotFound
+	}
+	spend.Cosigners = append([]Cosigner(nil), spend.Cosigners...)
+	return &spend, nil
+}
+
+// SaveSpend stores a copy of spend
+func (s *MemorySpendStore) SaveSpend(spend *MultisigSpend) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *spend
+	saved.Cosigners = append([]Cosigner(nil), spend.Cosigners...)
+	s.spends[spend.ID] = saved
+	return nil
+}
+
+// FileSpendStore keeps each spend as a JSON file named <id>.json in a directory
+type FileSpendStore struct {
+	dir string
+}
+
+// NewFileSpendStore creates a store in dir, creating the directory if needed
+func NewFileSpendStore(dir string) (*FileSpendStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create spend directory: %v", err)
+	}
+	return &FileSpendStore{dir: dir}, nil
+}
+
+// LoadSpend reads the spend file for id
+func (s *FileSpendStore) LoadSpend(id string) (*MultisigSpend, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSpendNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spend file: %v", err)
+	}
+
+	var spend MultisigSpend
+	if err := json.Unmarshal(data, &spend); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spend file: %v", err)
+	}
+	return &spend, nil
+}
+
+// SaveSpend writes the spend file, replacing it atomically
+func (s *FileSpendStore) SaveSpend(spend *MultisigSpend) error {
+	path, err := s.path(spend.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(spend, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal spend: %v", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write spend file: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace spend file: %v", err)
+	}
+	return nil
+}
+
+// path returns the file used for id, rejecting ids that would escape the directory
+func (s *FileSpendStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid spend id %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// MultisigCoordinator drives multisig spends from funding to broadcast
+//
+// A spend is funded from a watch-only wallet that holds the multisig descriptors,
+// then each cosigner either signs with a wallet on the node (SignWithWallet) or
+// returns a signed PSBT that is merged with AddPSBT or ImportPSBTFile. Every step
+// is saved to the store, so a spend can be picked up again with Load after a restart.
+// Steps that change a spend hold a lock on its id, so concurrent calls on the same
+// coordinator do not overwrite each other's signatures. Coordinators sharing a store
+// across processes are not coordinated.
+type MultisigCoordinator struct {
+	client *Client
+	store  MultisigSpendStore
+
+	mu    sync.Mutex
+	locks map[string]*spendLock
+}
+
+// spendLock serializes the load, modify and save steps on one spend
+type spendLock struct {
+	mu   sync.Mutex
+	refs int // callers holding or waiting for mu
+}
+
+// NewMultisigCoordinator creates a coordinator that keeps its spends in store
+func NewMultisigCoordinator(client *Client, store MultisigSpendStore) *MultisigCoordinator {
+	return &MultisigCoordinator{client: client, store: store, locks: make(map[string]*spendLock)}
+}
+
+// lock locks the spend with the given id and returns the function that unlocks it
+func (m *MultisigCoordinator) lock(id string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[id]
+	if !ok {
+		l = &spendLock{}
+		m.locks[id] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		m.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(m.locks, id)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// Create funds a new spend from watchWallet and saves it under id
+// outputs uses the same format as WalletCreateFundedPSBT. required is the number of
+// signatures each input needs.
+func (m *MultisigCoordinator) Create(id, watchWallet string, required int, cosigners []Cosigner, outputs CreateRawTransactionOutputs, options *FundOptions) (*MultisigSpend, error) {
+	if required < 1 || required > len(cosigners) {
+		return nil, fmt.Errorf("invalid threshold %d of %d cosigners", required, len(cosigners))
+	}
+	cosigners = append([]Cosigner(nil), cosigners...)
+	for i, cs := range cosigners {
+		fp, err := hex.DecodeString(cs.Fingerprint)
+		if err != nil || len(fp) != 4 {
+			return nil, fmt.Errorf("cosigner %d has an invalid fingerprint %q", i, cs.Fingerprint)
+		}
+		cosigners[i].Fingerprint = strings.ToLower(cs.Fingerprint)
+	}
+
+	unlock := m.lock(id)
+	defer unlock()
+	if _, err := m.store.LoadSpend(id); err == nil {
+		return nil, fmt.Errorf("multisig spend %q already exists", id)
+	} else if !errors.Is(err, ErrSpendNotFound) {
+		return nil, err
+	}
+
+	// Watch-only wallets need include_watching to select the multisig UTXOs
+	opts := FundOptions{}
+	if options != nil {
+		opts = *options
+	}
+	opts.IncludeWatching = true
+
+	funded, err := m.client.WalletCreateFundedPSBT(watchWallet, nil, outputs, 0, &opts, true)
+	if err != nil {
+		return nil, err
+	}
+	packet, err := psbt.DecodeBase64(funded.PSBT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode funded psbt: %v", err)
+	}
+	tx, err := packet.UnsignedTx()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	spend := &MultisigSpend{
+		ID:          id,
+		WatchWallet: watchWallet,
+		Required:    required,
+		Cosigners:   cosigners,
+		Status:      SpendPending,
+		PSBT:        funded.PSBT,
+		Fee:         funded.Fee,
+		TxID:        tx.TxID(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := m.save(spend); err != nil {
+		return nil, err
+	}
+	return spend, nil
+}
+
+// Load returns the saved spend with the given id
+func (m *MultisigCoordinator) Load(id string) (*MultisigSpend, error) {
+	return m.store.LoadSpend(id)
+}
+
+// SignWithWallet signs the spend with a signer wallet loaded on the node and merges the result
+func (m *MultisigCoordinator) SignWithWallet(id, walletName string) (*MultisigSpend, error) {
+	unlock := m.lock(id)
+	defer unlock()
+	return m.signWithWallet(id, walletName)
+}
+
+// signWithWallet is SignWithWallet for callers holding the lock on id
+func (m *MultisigCoordinator) signWithWallet(id, walletName string) (*MultisigSpend, error) {
+	spend, err := m.pending(id)
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := m.client.WalletProcessPSBT(walletName, spend.PSBT, true, "", true, false)
+	if err != nil {
+		return nil, err
+	}
+	return m.merge(spend, processed.PSBT)
+}
+
+// SignWithCosignerWallets signs with the wallet of every cosigner that has one and has not signed yet
+func (m *MultisigCoordinator) SignWithCosignerWallets(id string) (*MultisigSpend, error) {
+	unlock := m.lock(id)
+	defer unlock()
+
+	missing, err := m.Missing(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cs := range missing {
+		if cs.WalletName == "" {
+			continue
+		}
+		if _, err := m.signWithWallet(id, cs.WalletName); err != nil {
+			return nil, fmt.Errorf("cosigner %s: %w", cs.Name, err)
+		}
+	}
+	return m.store.LoadSpend(id)
+}
+
+// AddPSBT merges a PSBT signed elsewhere (base64) into the spend
+func (m *MultisigCoordinator) AddPSBT(id, signed string) (*MultisigSpend, error) {
+	unlock := m.lock(id)
+	defer unlock()
+
+	spend, err := m.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.merge(spend, strings.TrimSpace(signed))
+}
+
+// ImportPSBTFile merges a signed PSBT file, in either binary or base64 form, into the spend
+func (m *MultisigCoordinator) ImportPSBTFile(id, path string) (*MultisigSpend, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read psbt file: %v", err)
+	}
+	signed := string(data)
+	if bytes.HasPrefix(data, []byte("psbt\xff")) {
+		signed = base64.StdEncoding.EncodeToString(data)
+	}
+	return m.AddPSBT(id, signed)
+}
+
+// ExportPSBTFile writes the current combined PSBT in binary form for an offline cosigner
+func (m *MultisigCoordinator) ExportPSBTFile(id, path string) error {
+	spend, err := m.store.LoadSpend(id)
+	if err != nil {
+		return err
+	}
+	data, err := base64.StdEncoding.DecodeString(spend.PSBT)
+	if err != nil {
+		return fmt.Errorf("failed to decode stored psbt: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write psbt file: %v", err)
+	}
+	return nil
+}
+
+// Missing returns the cosigners that have not yet signed every input they have a key in
+// Once enough signatures are present the spend can be finalized even if this is not empty.
+func (m *MultisigCoordinator) Missing(id string) ([]Cosigner, error) {
+	spend, err := m.store.LoadSpend(id)
+	if err != nil {
+		return nil, err
+	}
+	if spend.Status != SpendPending {
+		return nil, nil
+	}
+	packet, err := psbt.DecodeBase64(spend.PSBT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode stored psbt: %v", err)
+	}
+
+	var missing []Cosigner
+	for _, cs := range spend.Cosigners {
+		if !cosignerSigned(packet, cs.Fingerprint) {
+			missing = append(missing, cs)
+		}
+	}
+	return missing, nil
+}
+
+// Finalize finalizes the spend once enough signatures are present and stores the network transaction
+func (m *MultisigCoordinator) Finalize(id string) (*MultisigSpend, error) {
+	unlock := m.lock(id)
+	defer unlock()
+	return m.finalize(id)
+}
+
+// finalize is Finalize for callers holding the lock on id
+func (m *MultisigCoordinator) finalize(id string) (*MultisigSpend, error) {
+	spend, err := m.store.LoadSpend(id)
+	if err != nil {
+		return nil, err
+	}
+	if spend.Status != SpendPending {
+		return spend, nil
+	}
+
+	result, err := m.client.FinalizePSBT(spend.PSBT, true)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Complete {
+		missing, err := m.Missing(id)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(missing))
+		for i, cs := range missing {
+			names[i] = cs.Name
+		}
+		return nil, fmt.Errorf("multisig spend %q is not fully signed, missing: %s", id, strings.Join(names, ", "))
+	}
+
+	spend.Hex = result.Hex
+	spend.Status = SpendFinalized
+	if err := m.save(spend); err != nil {
+		return nil, err
+	}
+	return spend, nil
+}
+
+// Broadcast finalizes the spend if needed and sends it to the network
+// maxfeerate is passed to sendrawtransaction; 0 uses the node default.
+func (m *MultisigCoordinator) Broadcast(id string, maxfeerate float64) (*MultisigSpend, error) {
+	unlock := m.lock(id)
+	defer unlock()
+
+	spend, err := m.finalize(id)
+	if err != nil {
+		return nil, err
+	}
+	if spend.Status == SpendBroadcast {
+		return spend, nil
+	}
+
+	txid, err := m.client.SendRawTransaction(spend.Hex, maxfeerate)
+	if err != nil {
+		return nil, err
+	}
+
+	spend.TxID = txid
+	spend.Status = SpendBroadcast
+	if err := m.save(spend); err != nil {
+		return nil, err
+	}
+	return spend, nil
+}
+
+// pending loads a spend that still accepts signatures
+func (m *MultisigCoordinator) pending(id string) (*MultisigSpend, error) {
+	spend, err := m.store.LoadSpend(id)
+	if err != nil {
+		return nil, err
+	}
+	if spend.Status != SpendPending {
+		return nil, fmt.Errorf("multisig spend %q is already %s", id, spend.Status)
+	}
+	return spend, nil
+}
+
+// merge combines signed into the spend after checking it is for the same transaction
+func (m *MultisigCoordinator) merge(spend *MultisigSpend, signed string) (*MultisigSpend, error) {
+	packet, err := psbt.DecodeBase64(signed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signed psbt: %v", err)
+	}
+	tx, err := packet.UnsignedTx()
+	if err != nil {
+		return nil, err
+	}
+	if txid := tx.TxID(); txid != spend.TxID {
+		return nil, fmt.Errorf("psbt spends transaction %s, expected %s", txid, spend.TxID)
+	}
+
+	combined, err := m.client.CombinePSBT([]string{spend.PSBT, signed})
+	if err != nil {
+		return nil, err
+	}
+
+	spend.PSBT = combined
+	if err := m.save(spend); err != nil {
+		return nil, err
+	}
+	return spend, nil
+}
+
+// save stamps and persists the spend
+func (m *MultisigCoordinator) save(spend *MultisigSpend) error {
+	spend.UpdatedAt = time.Now().UTC()
+	if err := m.store.SaveSpend(spend); err != nil {
+		return fmt.Errorf("failed to save multisig spend: %v", err)
+	}
+	return nil
+}
+
+// cosignerSigned reports whether every input holding a key of the cosigner has its signature
+// Finalized inputs count as signed, since finalizing drops the partial signatures.
+func cosignerSigned(packet *psbt.Packet, fingerprint string) bool {
+	found := false
+	for _, in := range packet.Inputs {
+		if in.FinalScriptSig != nil || in.FinalScriptWitness != nil {
+			found = true
+			continue
+		}
+
+		for _, d := range in.Bip32Derivations {
+			if hex.EncodeToString(d.Fingerprint[:]) != fingerprint {
+				continue
+			}
+			found = true
+			if !hasPartialSig(in, d.PubKey) {
+				return false
+			}
+		}
+
+		for _, d := range in.TaprootBip32Derivs {
+			if hex.EncodeToString(d.Fingerprint[:]) != fingerprint {
+				continue
+			}
+			found = true
+			if !hasTaprootSig(in, d) {
+				return false
+			}
+		}
+	}
+	return found
+}
+
+// hasPartialSig reports whether the input has an ECDSA signature for pubKey
+func hasPartialSig(in *psbt.Input, pubKey []byte) bool {
+	for _, sig := range in.PartialSigs {
+		if bytes.Equal(sig.PubKey, pubKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTaprootSig reports whether the input has a key path or script path signature for the derived key
+func hasTaprootSig(in *psbt.Input, d psbt.TaprootBip32Derivation) bool {
+	// Keys without leaf hashes are the internal key and sign through the key path
+	if len(d.LeafHashes) == 0 {
+		return in.TaprootKeySig != nil
+	}
+	for _, sig := range in.TaprootScriptSigs {
+		if bytes.Equal(sig.XOnlyPubKey, d.XOnlyPubKey) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package btcrpc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/koinvote/btcrpc/descriptor"
+)
+
+// MultisigScriptType selects the output script used by a descriptor multisig wallet
+type MultisigScriptType string
+
+const (
+	// MultisigWSH is native segwit P2WSH, wsh(sortedmulti(...))
+	MultisigWSH MultisigScriptType = "wsh"
+	// MultisigSHWSH is P2WSH nested in P2SH, sh(wsh(sortedmulti(...)))
+	MultisigSHWSH MultisigScriptType = "sh-wsh"
+	// MultisigTR is a taproot script path multisig, tr(NUMS,sortedmulti_a(...))
+	MultisigTR MultisigScriptType = "tr"
+)
+
+// unspendableKey is the BIP341 NUMS point H, used as the taproot internal key so only the script path can spend
+const unspendableKey = "50929b74c1a04954b78b4b6035e97a5e078a5a0f28ec96d547bfee9ace803ac0"
+
+// MultisigKey is a cosigner xpub with its key origin
+type MultisigKey struct {
+	Fingerprint string // master key fingerprint, 8 hex characters
+	Path        string // derivation path from the master key to Xpub, e.g. "m/48'/0'/0'/2'"
+	Xpub        string // account level extended public key
+}
+
+// MultisigDescriptors holds the receive and change descriptors of a multisig wallet
+type MultisigDescriptors struct {
+	Receive         string   // receive descriptor with checksum
+	Change          string   // change descriptor with checksum
+	Addresses       []string // first receive addresses
+	ChangeAddresses []string // first change addresses
+}
+
+// BuildMultisigDescriptors builds the sortedmulti receive (/0/*) and change (/1/*) descriptors
+// for a required-of-len(keys) multisig, with checksums. No node is needed.
+func BuildMultisigDescriptors(required int, keys []MultisigKey, scriptType MultisigScriptType) (receive, change string, err error) {
+	if required < 1 || required > len(keys) {
+		return "", "", fmt.Errorf("invalid threshold %d of %d keys", required, len(keys))
+	}
+
+	if receive, err = buildMultisigDescriptor(required, keys, scriptType, 0); err != nil {
+		return "", "", err
+	}
+	if change, err = buildMultisigDescriptor(required, keys, scriptType, 1); err != nil {
+		return "", "", err
+	}
+	return receive, change, nil
+}
+
+// buildMultisigDescriptor builds the descriptor for one branch (0 receive, 1 change)
+func buildMultisigDescriptor(required int, keys []MultisigKey, scriptType MultisigScriptType, branch int) (string, error) {
+	args := make([]string, 0, len(keys)+1)
+	args = append(args, fmt.Sprint(required))
+	for i, k := range keys {
+		fp, err := hex.DecodeString(k.Fingerprint)
+		if err != nil || len(fp) != 4 {
+			return "", fmt.Errorf("key %d has an invalid fingerprint %q", i, k.Fingerprint)
+		}
+		origin := strings.ToLower(k.Fingerprint)
+		if path := strings.Trim(strings.TrimPrefix(k.Path, "m"), "/"); path != "" {
+			origin += "/" + path
+		}
+		args = append(args, fmt.Sprintf("[%s]%s/%d/*", origin, k.Xpub, branch))
+	}
+
+	var desc string
+	switch scriptType {
+	case MultisigWSH:
+		desc = "wsh(sortedmulti(" + strings.Join(args, ",") + "))"
+	case MultisigSHWSH:
+		desc = "sh(wsh(sortedmulti(" + strings.Join(args, ",") + ")))"
+	case MultisigTR:
+		desc = "tr(" + unspendableKey + ",sortedmulti_a(" + strings.Join(args, ",") + "))"
+	default:
+		return "", fmt.Errorf("unsupported multisig script type %q", scriptType)
+	}
+
+	// Parsing checks the keys, the threshold limits and that no private keys slipped in
+	parsed, err := descriptor.Parse(desc)
+	if err != nil {
+		return "", err
+	}
+	if parsed.HasPrivateKeys() {
+		return "", fmt.Errorf("multisig keys must be extended public keys")
+	}
+	return parsed.StringWithChecksum()
+}
+
+// ImportMultisigDescriptors builds the multisig descriptors and imports them as the active
+// receive and change descriptors of walletName, which should be a blank descriptor wallet
+// with private keys disabled. It returns the first count receive and change addresses
+// so they can be checked against the cosigners' own wallets.
+// timestamp controls the rescan; TimestampNow (the zero value) suits a wallet that has not
+// received funds yet, TimestampGenesis rescans the whole chain.
+func (c *Client) ImportMultisigDescriptors(walletName string, required int, keys []MultisigKey, scriptType MultisigScriptType, timestamp DescriptorTimestamp, count int) (*MultisigDescriptors, error) {
+	receive, change, err := BuildMultisigDescriptors(required, keys, scriptType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Import at least the default keypool size so the wallet watches enough addresses
+	rng := DescriptorRange{0, max(count, 1000) - 1}
+	requests := []ImportDescriptorRequest{
+		{Desc: receive, Active: true, Range: &rng, Timestamp: timestamp},
+		{Desc: change, Active: true, Range: &rng, Timestamp: timestamp, Internal: true},
+	}
+
+	results, err := c.ImportDescriptors(walletName, requests)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if !result.Success {
+			msg := "unknown error"
+			if result.Error != nil {
+				msg = result.Error.Message
+			}
+			return nil, fmt.Errorf("failed to import descriptor %s: %s", requests[i].Desc, msg)
+		}
+	}
+
+	descs := &MultisigDescriptors{Receive: receive, Change: change}
+	if count > 0 {
+		first := &DescriptorRange{0, count - 1}
+		if descs.Addresses, err = c.DeriveAddresses(receive, first); err != nil {
+			return nil, err
+		}
+		if descs.ChangeAddresses, err = c.DeriveAddresses(change, first); err != nil {
+			return nil, err
+		}
+	}
+
+	return descs, nil
+}
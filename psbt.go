@@ -0,0 +1,261 @@
+package btcrpc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// === PSBT Creation Functions ===
+
+// CreatePSBT creates a transaction in the Partially Signed Transaction format.
+// Outputs are created in the order given, including OP_RETURN data outputs.
+func (c *Client) CreatePSBT(inputs []CreateRawTransactionInput, outputs CreateRawTransactionOutputs, locktime int64, replaceable bool) (string, error) {
+	params := []interface{}{inputs, outputs}
+
+	if locktime != 0 || replaceable {
+		params = append(params, locktime)
+	}
+
+	if replaceable {
+		params = append(params, replaceable)
+	}
+
+	resp, err := c.call("createpsbt", params)
+	if err != nil {
+		return "", fmt.Errorf("createpsbt RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return "", fmt.Errorf("createpsbt RPC error: %s", resp.Error.Message)
+	}
+
+	var result string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal createpsbt result: %w", err)
+	}
+
+	return result, nil
+}
+
+// WalletCreateFundedPSBT creates and funds a PSBT with the wallet's inputs, adding change if needed.
+// Outputs keep the order given; options may be nil to use the wallet defaults.
+func (c *Client) WalletCreateFundedPSBT(walletName string, inputs []CreateRawTransactionInput, outputs CreateRawTransactionOutputs, locktime int64, options *FundOptions, bip32derivs bool) (*WalletCreateFundedPSBTResponse, error) {
+	if inputs == nil {
+		inputs = []CreateRawTransactionInput{}
+	}
+
+	params := []interface{}{inputs, outputs, locktime}
+
+	if options != nil {
+		params = append(params, options)
+	} else {
+		params = append(params, nil)
+	}
+
+	params = append(params, bip32derivs)
+
+	resp, err := c.callWithWallet("walletcreatefundedpsbt", params, walletName)
+	if err != nil {
+		return nil, fmt.Errorf("walletcreatefundedpsbt RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("walletcreatefundedpsbt RPC error: %s", resp.Error.Message)
+	}
+
+	var result WalletCreateFundedPSBTResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal walletcreatefundedpsbt result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ConvertToPSBT converts a network serialized transaction to a PSBT.
+// iswitness may be nil to let the node guess the serialization format.
+func (c *Client) ConvertToPSBT(hexstring string, permitSigData bool, iswitness *bool) (string, error) {
+	params := []interface{}{hexstring, permitSigData}
+
+	if iswitness != nil {
+		params = append(params, *iswitness)
+	}
+
+	resp, err := c.call("converttopsbt", params)
+	if err != nil {
+		return "", fmt.Errorf("converttopsbt RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return "", fmt.Errorf("converttopsbt RPC error: %s", resp.Error.Message)
+	}
+
+	var result string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal converttopsbt result: %w", err)
+	}
+
+	return result, nil
+}
+
+// === PSBT Update and Signing Functions ===
+
+// UTXOUpdatePSBT updates all segwit inputs of a PSBT with UTXO data from the UTXO set or mempool.
+// descriptors optionally lists descriptors used to add BIP32 derivation paths and scripts.
+func (c *Client) UTXOUpdatePSBT(psbt string, descriptors []string) (string, error) {
+	params := []interface{}{psbt}
+
+	if len(descriptors) > 0 {
+		params = append(params, descriptors)
+	}
+
+	resp, err := c.call("utxoupdatepsbt", params)
+	if err != nil {
+		return "", fmt.Errorf("utxoupdatepsbt RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return "", fmt.Errorf("utxoupdatepsbt RPC error: %s", resp.Error.Message)
+	}
+
+	var result string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal utxoupdatepsbt result: %w", err)
+	}
+
+	return result, nil
+}
+
+// WalletProcessPSBT updates a PSBT with input information from the wallet and optionally signs and finalizes it.
+// sighashtype defaults to "DEFAULT" when empty.
+func (c *Client) WalletProcessPSBT(walletName, psbt string, sign bool, sighashtype string, bip32derivs, finalize bool) (*WalletProcessPSBTResponse, error) {
+	if sighashtype == "" {
+		sighashtype = "DEFAULT"
+	}
+
+	params := []interface{}{psbt, sign, sighashtype, bip32derivs, finalize}
+
+	resp, err := c.callWithWallet("walletprocesspsbt", params, walletName)
+	if err != nil {
+		return nil, fmt.Errorf("walletprocesspsbt RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("walletprocesspsbt RPC error: %s", resp.Error.Message)
+	}
+
+	var result WalletProcessPSBTResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal walletprocesspsbt result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// === PSBT Combination and Finalization Functions ===
+
+// CombinePSBT combines multiple PSBTs for the same transaction into one.
+func (c *Client) CombinePSBT(psbts []string) (string, error) {
+	params := []interface{}{psbts}
+
+	resp, err := c.call("combinepsbt", params)
+	if err != nil {
+		return "", fmt.Errorf("combinepsbt RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return "", fmt.Errorf("combinepsbt RPC error: %s", resp.Error.Message)
+	}
+
+	var result string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal combinepsbt result: %w", err)
+	}
+
+	return result, nil
+}
+
+// JoinPSBTs joins multiple distinct PSBTs with different inputs and outputs into one.
+func (c *Client) JoinPSBTs(psbts []string) (string, error) {
+	params := []interface{}{psbts}
+
+	resp, err := c.call("joinpsbts", params)
+	if err != nil {
+		return "", fmt.Errorf("joinpsbts RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return "", fmt.Errorf("joinpsbts RPC error: %s", resp.Error.Message)
+	}
+
+	var result string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal joinpsbts result: %w", err)
+	}
+
+	return result, nil
+}
+
+// FinalizePSBT finalizes the inputs of a PSBT and, if complete and extract is true, extracts the network transaction.
+func (c *Client) FinalizePSBT(psbt string, extract bool) (*FinalizePSBTResponse, error) {
+	params := []interface{}{psbt, extract}
+
+	resp, err := c.call("finalizepsbt", params)
+	if err != nil {
+		return nil, fmt.Errorf("finalizepsbt RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("finalizepsbt RPC error: %s", resp.Error.Message)
+	}
+
+	var result FinalizePSBTResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal finalizepsbt result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// === PSBT Inspection Functions ===
+
+// DecodePSBT returns a JSON object representing the serialized, base64-encoded PSBT.
+func (c *Client) DecodePSBT(psbt string) (*DecodePSBTResponse, error) {
+	params := []interface{}{psbt}
+
+	resp, err := c.call("decodepsbt", params)
+	if err != nil {
+		return nil, fmt.Errorf("decodepsbt RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("decodepsbt RPC error: %s", resp.Error.Message)
+	}
+
+	var result DecodePSBTResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decodepsbt result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// AnalyzePSBT analyzes a PSBT and reports the current status and next role of each input.
+func (c *Client) AnalyzePSBT(psbt string) (*AnalyzePSBTResponse, error) {
+	params := []interface{}{psbt}
+
+	resp, err := c.call("analyzepsbt", params)
+	if err != nil {
+		return nil, fmt.Errorf("analyzepsbt RPC call failed: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("analyzepsbt RPC error: %s", resp.Error.Message)
+	}
+
+	var result AnalyzePSBTResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal analyzepsbt result: %w", err)
+	}
+
+	return &result, nil
+}
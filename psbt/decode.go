@@ -0,0 +1,613 @@
+package psbt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidMagic is returned when the input does not start with the PSBT magic bytes
+var ErrInvalidMagic = errors.New("psbt: invalid magic bytes")
+
+// pair is a raw key-value pair read from a map
+type pair struct {
+	key     []byte // complete key including the type
+	keyType uint64
+	keyData []byte
+	value   []byte
+}
+
+// DecodeBase64 parses a base64 encoded PSBT, the format used by the PSBT RPCs
+func DecodeBase64(s string) (*Packet, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("psbt: invalid base64: %v", err)
+	}
+	return Decode(b)
+}
+
+// Decode parses a binary PSBT
+func Decode(b []byte) (*Packet, error) {
+	if !bytes.HasPrefix(b, magic) {
+		return nil, ErrInvalidMagic
+	}
+	r := bytes.NewReader(b[len(magic):])
+
+	pairs, err := readMap(r)
+	if err != nil {
+		return nil, fmt.Errorf("psbt: global map: %v", err)
+	}
+	p := &Packet{}
+	inputCount, outputCount, err := p.Global.decode(pairs)
+	if err != nil {
+		return nil, fmt.Errorf("psbt: global map: %v", err)
+	}
+
+	if p.Global.Version < 2 {
+		if p.Global.UnsignedTx == nil {
+			return nil, errors.New("psbt: missing unsigned transaction")
+		}
+		inputCount = uint64(len(p.Global.UnsignedTx.Inputs))
+		outputCount = uint64(len(p.Global.UnsignedTx.Outputs))
+	}
+
+	for i := uint64(0); i < inputCount; i++ {
+		if r.Len() == 0 {
+			return nil, fmt.Errorf("psbt: expected %d inputs, found %d", inputCount, i)
+		}
+		pairs, err := readMap(r)
+		if err != nil {
+			return nil, fmt.Errorf("psbt: input %d: %v", i, err)
+		}
+		in := &Input{}
+		if err := in.decode(pairs, p.Global.Version); err != nil {
+			return nil, fmt.Errorf("psbt: input %d: %v", i, err)
+		}
+		p.Inputs = append(p.Inputs, in)
+	}
+
+	for i := uint64(0); i < outputCount; i++ {
+		if r.Len() == 0 {
+			return nil, fmt.Errorf("psbt: expected %d outputs, found %d", outputCount, i)
+		}
+		pairs, err := readMap(r)
+		if err != nil {
+			return nil, fmt.Errorf("psbt: output %d: %v", i, err)
+		}
+		out := &Output{}
+		if err := out.decode(pairs, p.Global.Version); err != nil {
+			return nil, fmt.Errorf("psbt: output %d: %v", i, err)
+		}
+		p.Outputs = append(p.Outputs, out)
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("psbt: %d trailing bytes", r.Len())
+	}
+	if err := p.checkInputUTXOs(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// checkInputUTXOs verifies that non-witness UTXOs match the outpoints they are attached to
+func (p *Packet) checkInputUTXOs() error {
+	tx, err := p.UnsignedTx()
+	if err != nil {
+		return err
+	}
+	for i, in := range p.Inputs {
+		if in.NonWitnessUTXO == nil {
+			continue
+		}
+		prev := tx.Inputs[i]
+		if doubleSHA256(in.NonWitnessUTXO.SerializeNoWitness()) != prev.PrevTxID {
+			return fmt.Errorf("psbt: input %d non-witness UTXO does not match the spent txid", i)
+		}
+		if int(prev.PrevIndex) >= len(in.NonWitnessUTXO.Outputs) {
+			return fmt.Errorf("psbt: input %d spends missing output %d of its non-witness UTXO", i, prev.PrevIndex)
+		}
+	}
+	return nil
+}
+
+// readMap reads key-value pairs up to the 0x00 separator, rejecting duplicate keys
+func readMap(r *bytes.Reader) ([]pair, error) {
+	var pairs []pair
+	seen := make(map[string]bool)
+	for {
+		key, err := readVarBytes(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading key: %v", err)
+		}
+		if len(key) == 0 {
+			return pairs, nil
+		}
+		value, err := readVarBytes(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading value for key %s: %v", hexKey(key), err)
+		}
+		if seen[string(key)] {
+			return nil, fmt.Errorf("duplicate key %s", hexKey(key))
+		}
+		seen[string(key)] = true
+
+		kr := bytes.NewReader(key)
+		keyType, err := readCompactSize(kr)
+		if err != nil {
+			return nil, fmt.Errorf("reading key type: %v", err)
+		}
+		pairs = append(pairs, pair{key: key, keyType: keyType, keyData: key[len(key)-kr.Len():], value: value})
+	}
+}
+
+// decode fills the global map and returns the version 2 input and output counts
+func (g *Global) decode(pairs []pair) (inputCount, outputCount uint64, err error) {
+	var hasInputCount, hasOutputCount bool
+	for _, kv := range pairs {
+		switch kv.keyType {
+		case GlobalUnsignedTx:
+			if err := noKeyData(kv); err != nil {
+				return 0, 0, err
+			}
+			// The unsigned transaction is always serialized without witnesses
+			tx, err := decodeTx(kv.value, false)
+			if err != nil {
+				return 0, 0, fmt.Errorf("unsigned transaction: %v", err)
+			}
+			for i, in := range tx.Inputs {
+				if len(in.ScriptSig) > 0 {
+					return 0, 0, fmt.Errorf("unsigned transaction input %d has a scriptSig", i)
+				}
+			}
+			g.UnsignedTx = tx
+		case GlobalXpub:
+			if len(kv.keyData) != 78 {
+				return 0, 0, fmt.Errorf("xpub key is %d bytes, expected 78", len(kv.keyData))
+			}
+			fp, path, err := decodeOrigin(kv.value)
+			if err != nil {
+				return 0, 0, fmt.Errorf("xpub origin: %v", err)
+			}
+			g.Xpubs = append(g.Xpubs, Xpub{ExtendedKey: kv.keyData, Fingerprint: fp, Path: path})
+		case GlobalTxVersion:
+			v, err := fixedUint32(kv)
+			if err != nil {
+				return 0, 0, err
+			}
+			version := int32(v)
+			g.TxVersion = &version
+		case GlobalFallbackLocktime:
+			v, err := fixedUint32(kv)
+			if err != nil {
+				return 0, 0, err
+			}
+			g.FallbackLocktime = &v
+		case GlobalInputCount, GlobalOutputCount:
+			if err := noKeyData(kv); err != nil {
+				return 0, 0, err
+			}
+			vr := bytes.NewReader(kv.value)
+			n, err := readCompactSize(vr)
+			if err != nil || vr.Len() != 0 {
+				return 0, 0, fmt.Errorf("invalid count for key %s", hexKey(kv.key))
+			}
+			if kv.keyType == GlobalInputCount {
+				inputCount, hasInputCount = n, true
+			} else {
+				outputCount, hasOutputCount = n, true
+			}
+		case GlobalTxModifiable:
+			if err := noKeyData(kv); err != nil {
+				return 0, 0, err
+			}
+			if len(kv.value) != 1 {
+				return 0, 0, errors.New("tx modifiable flags must be 1 byte")
+			}
+			flags := kv.value[0]
+			g.TxModifiable = &flags
+		case GlobalVersion:
+			v, err := fixedUint32(kv)
+			if err != nil {
+				return 0, 0, err
+			}
+			if v != 0 && v != 2 {
+				return 0, 0, fmt.Errorf("unsupported PSBT version %d", v)
+			}
+			g.Version = v
+		default:
+			g.Unknowns = append(g.Unknowns, Unknown{Key: kv.key, Value: kv.value})
+		}
+	}
+
+	if g.Version >= 2 {
+		switch {
+		case g.UnsignedTx != nil:
+			return 0, 0, errors.New("version 2 PSBT must not contain an unsigned transaction")
+		case g.TxVersion == nil:
+			return 0, 0, errors.New("version 2 PSBT is missing the transaction version")
+		case !hasInputCount || !hasOutputCount:
+			return 0, 0, errors.New("version 2 PSBT is missing the input or output count")
+		}
+	} else if g.TxVersion != nil || g.FallbackLocktime != nil || hasInputCount || hasOutputCount || g.TxModifiable != nil {
+		return 0, 0, errors.New("version 0 PSBT must not contain version 2 global fields")
+	}
+	return inputCount, outputCount, nil
+}
+
+// decode fills an input map
+func (in *Input) decode(pairs []pair, version uint32) error {
+	for _, kv := range pairs {
+		var err error
+		switch kv.keyType {
+		case InPreviousTxID, InOutputIndex, InSequence, InRequiredTimeLocktime, InRequiredHeightLocktime:
+			// A version 0 map only knows these types as keyless fields, with key data they are unknown
+			if version < 2 && len(kv.keyData) != 0 {
+				in.Unknowns = append(in.Unknowns, Unknown{Key: kv.key, Value: kv.value})
+				continue
+			}
+		}
+		switch kv.keyType {
+		case InNonWitnessUTXO:
+			if err = noKeyData(kv); err == nil {
+				in.NonWitnessUTXO, err = decodeTx(kv.value, true)
+			}
+		case InWitnessUTXO:
+			if err = noKeyData(kv); err == nil {
+				in.WitnessUTXO, err = decodeTxOut(kv.value)
+			}
+		case InPartialSig:
+			if err = checkPubKey(kv.keyData); err == nil {
+				in.PartialSigs = append(in.PartialSigs, PartialSig{PubKey: kv.keyData, Signature: kv.value})
+			}
+		case InSighashType:
+			var v uint32
+			if v, err = fixedUint32(kv); err == nil {
+				in.SighashType = &v
+			}
+		case InRedeemScript:
+			if err = noKeyData(kv); err == nil {
+				in.RedeemScript = kv.value
+			}
+		case InWitnessScript:
+			if err = noKeyData(kv); err == nil {
+				in.WitnessScript = kv.value
+			}
+		case InBip32Derivation:
+			var d *Bip32Derivation
+			if d, err = decodeBip32Derivation(kv); err == nil {
+				in.Bip32Derivations = append(in.Bip32Derivations, *d)
+			}
+		case InFinalScriptSig:
+			if err = noKeyData(kv); err == nil {
+				in.FinalScriptSig = kv.value
+			}
+		case InFinalScriptWitness:
+			if err = noKeyData(kv); err == nil {
+				vr := bytes.NewReader(kv.value)
+				if in.FinalScriptWitness, err = readWitness(vr); err == nil && vr.Len() != 0 {
+					err = errors.New("trailing bytes after final script witness")
+				}
+				if err == nil && in.FinalScriptWitness == nil {
+					in.FinalScriptWitness = [][]byte{}
+				}
+			}
+		case InRipemd160, InHash160:
+			in.appendPreimage(kv, 20, &err)
+		case InSha256, InHash256:
+			in.appendPreimage(kv, 32, &err)
+		case InPreviousTxID:
+			if err = noKeyData(kv); err == nil {
+				if len(kv.value) != 32 {
+					err = errors.New("previous txid must be 32 bytes")
+				} else {
+					var txid [32]byte
+					copy(txid[:], kv.value)
+					in.PreviousTxID = &txid
+				}
+			}
+		case InOutputIndex:
+			in.OutputIndex, err = optionalUint32(kv)
+		case InSequence:
+			in.Sequence, err = optionalUint32(kv)
+		case InRequiredTimeLocktime:
+			if in.RequiredTimeLocktime, err = optionalUint32(kv); err == nil && *in.RequiredTimeLocktime < 500000000 {
+				err = errors.New("required time locktime must be at least 500000000")
+			}
+		case InRequiredHeightLocktime:
+			if in.RequiredHeightLocktime, err = optionalUint32(kv); err == nil && *in.RequiredHeightLocktime >= 500000000 {
+				err = errors.New("required height locktime must be below 500000000")
+			}
+		case InTapKeySig:
+			if err = noKeyData(kv); err == nil {
+				if len(kv.value) != 64 && len(kv.value) != 65 {
+					err = errors.New("taproot key signature must be 64 or 65 bytes")
+				}
+				in.TaprootKeySig = kv.value
+			}
+		case InTapScriptSig:
+			switch {
+			case len(kv.keyData) != 64:
+				err = errors.New("taproot script signature key must be 64 bytes")
+			case len(kv.value) != 64 && len(kv.value) != 65:
+				err = errors.New("taproot script signature must be 64 or 65 bytes")
+			default:
+				in.TaprootScriptSigs = append(in.TaprootScriptSigs, TaprootScriptSig{
+					XOnlyPubKey: kv.keyData[:32],
+					LeafHash:    kv.keyData[32:],
+					Signature:   kv.value,
+				})
+			}
+		case InTapLeafScript:
+			switch {
+			case len(kv.keyData) < 33 || (len(kv.keyData)-33)%32 != 0 || (len(kv.keyData)-33)/32 > 128:
+				err = errors.New("invalid taproot control block length")
+			case len(kv.value) == 0:
+				err = errors.New("taproot leaf script is missing its leaf version")
+			default:
+				in.TaprootLeafScripts = append(in.TaprootLeafScripts, TaprootLeafScript{
+					ControlBlock: kv.keyData,
+					Script:       kv.value[:len(kv.value)-1],
+					LeafVersion:  kv.value[len(kv.value)-1],
+				})
+			}
+		case InTapBip32Derivation:
+			var d *TaprootBip32Derivation
+			if d, err = decodeTaprootBip32Derivation(kv); err == nil {
+				in.TaprootBip32Derivs = append(in.TaprootBip32Derivs, *d)
+			}
+		case InTapInternalKey:
+			in.TaprootInternalKey, err = fixedBytes(kv, 32)
+		case InTapMerkleRoot:
+			in.TaprootMerkleRoot, err = fixedBytes(kv, 32)
+		default:
+			in.Unknowns = append(in.Unknowns, Unknown{Key: kv.key, Value: kv.value})
+		}
+		if err != nil {
+			return fmt.Errorf("key %s: %v", hexKey(kv.key), err)
+		}
+	}
+
+	isV2Field := in.PreviousTxID != nil || in.OutputIndex != nil || in.Sequence != nil ||
+		in.RequiredTimeLocktime != nil || in.RequiredHeightLocktime != nil
+	if version >= 2 {
+		if in.PreviousTxID == nil || in.OutputIndex == nil {
+			return errors.New("version 2 input is missing its previous txid or output index")
+		}
+	} else if isV2Field {
+		return errors.New("version 0 input must not contain version 2 fields")
+	}
+	return nil
+}
+
+// appendPreimage decodes a hash preimage pair with the given hash length
+func (in *Input) appendPreimage(kv pair, hashLen int, err *error) {
+	if len(kv.keyData) != hashLen {
+		*err = fmt.Errorf("preimage hash must be %d bytes", hashLen)
+		return
+	}
+	pre := Preimage{Hash: kv.keyData, Preimage: kv.value}
+	switch kv.keyType {
+	case InRipemd160:
+		in.Ripemd160Preimages = append(in.Ripemd160Preimages, pre)
+	case InSha256:
+		in.Sha256Preimages = append(in.Sha256Preimages, pre)
+	case InHash160:
+		in.Hash160Preimages = append(in.Hash160Preimages, pre)
+	case InHash256:
+		in.Hash256Preimages = append(in.Hash256Preimages, pre)
+	}
+}
+
+// decode fills an output map
+func (out *Output) decode(pairs []pair, version uint32) error {
+	for _, kv := range pairs {
+		var err error
+		switch kv.keyType {
+		case OutAmount, OutScript:
+			// As for inputs, key data makes these unknown types in a version 0 map
+			if version < 2 && len(kv.keyData) != 0 {
+				out.Unknowns = append(out.Unknowns, Unknown{Key: kv.key, Value: kv.value})
+				continue
+			}
+		}
+		switch kv.keyType {
+		case OutRedeemScript:
+			if err = noKeyData(kv); err == nil {
+				out.RedeemScript = kv.value
+			}
+		case OutWitnessScript:
+			if err = noKeyData(kv); err == nil {
+				out.WitnessScript = kv.value
+			}
+		case OutBip32Derivation:
+			var d *Bip32Derivation
+			if d, err = decodeBip32Derivation(kv); err == nil {
+				out.Bip32Derivations = append(out.Bip32Derivations, *d)
+			}
+		case OutAmount:
+			if err = noKeyData(kv); err == nil {
+				if len(kv.value) != 8 {
+					err = errors.New("amount must be 8 bytes")
+				} else {
+					amount := int64(binary.LittleEndian.Uint64(kv.value))
+					out.Amount = &amount
+				}
+			}
+		case OutScript:
+			if err = noKeyData(kv); err == nil {
+				out.Script = kv.value
+			}
+		case OutTapInternalKey:
+			out.TaprootInternalKey, err = fixedBytes(kv, 32)
+		case OutTapTree:
+			if err = noKeyData(kv); err == nil {
+				out.TaprootTree, err = decodeTaprootTree(kv.value)
+			}
+		case OutTapBip32Derivation:
+			var d *TaprootBip32Derivation
+			if d, err = decodeTaprootBip32Derivation(kv); err == nil {
+				out.TaprootBip32Derivs = append(out.TaprootBip32Derivs, *d)
+			}
+		default:
+			out.Unknowns = append(out.Unknowns, Unknown{Key: kv.key, Value: kv.value})
+		}
+		if err != nil {
+			return fmt.Errorf("key %s: %v", hexKey(kv.key), err)
+		}
+	}
+
+	if version >= 2 {
+		if out.Amount == nil || out.Script == nil {
+			return errors.New("version 2 output is missing its amount or script")
+		}
+	} else if out.Amount != nil || out.Script != nil {
+		return errors.New("version 0 output must not contain version 2 fields")
+	}
+	return nil
+}
+
+// decodeTxOut parses a serialized transaction output
+func decodeTxOut(b []byte) (*TxOut, error) {
+	r := bytes.NewReader(b)
+	out, err := readTxOut(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("trailing bytes after output")
+	}
+	return out, nil
+}
+
+// decodeOrigin parses a 4-byte fingerprint followed by 32-bit path steps
+func decodeOrigin(b []byte) ([4]byte, []uint32, error) {
+	var fp [4]byte
+	if len(b) < 4 || len(b)%4 != 0 {
+		return fp, nil, fmt.Errorf("key origin has invalid length %d", len(b))
+	}
+	copy(fp[:], b[:4])
+	path := make([]uint32, 0, len(b)/4-1)
+	for i := 4; i < len(b); i += 4 {
+		path = append(path, binary.LittleEndian.Uint32(b[i:]))
+	}
+	return fp, path, nil
+}
+
+// decodeBip32Derivation parses a BIP32 derivation pair keyed by public key
+func decodeBip32Derivation(kv pair) (*Bip32Derivation, error) {
+	if err := checkPubKey(kv.keyData); err != nil {
+		return nil, err
+	}
+	fp, path, err := decodeOrigin(kv.value)
+	if err != nil {
+		return nil, err
+	}
+	return &Bip32Derivation{PubKey: kv.keyData, Fingerprint: fp, Path: path}, nil
+}
+
+// decodeTaprootBip32Derivation parses a taproot BIP32 derivation pair keyed by x-only public key
+func decodeTaprootBip32Derivation(kv pair) (*TaprootBip32Derivation, error) {
+	if len(kv.keyData) != 32 {
+		return nil, errors.New("taproot derivation key must be a 32-byte x-only public key")
+	}
+	r := bytes.NewReader(kv.value)
+	n, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len())/32 {
+		return nil, errors.New("taproot derivation leaf hashes exceed value")
+	}
+	d := &TaprootBip32Derivation{XOnlyPubKey: kv.keyData}
+	for i := uint64(0); i < n; i++ {
+		h := make([]byte, 32)
+		if _, err := io.ReadFull(r, h); err != nil {
+			return nil, err
+		}
+		d.LeafHashes = append(d.LeafHashes, h)
+	}
+	rest := kv.value[len(kv.value)-r.Len():]
+	if d.Fingerprint, d.Path, err = decodeOrigin(rest); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// decodeTaprootTree parses the depth-first leaf list of an output taproot tree
+func decodeTaprootTree(b []byte) ([]TaprootTreeLeaf, error) {
+	r := bytes.NewReader(b)
+	var leaves []TaprootTreeLeaf
+	for r.Len() > 0 {
+		depth, _ := r.ReadByte()
+		leafVersion, err := r.ReadByte()
+		if err != nil {
+			return nil, errors.New("truncated taproot tree leaf")
+		}
+		if depth > 128 {
+			return nil, fmt.Errorf("taproot tree depth %d exceeds 128", depth)
+		}
+		script, err := readVarBytes(r)
+		if err != nil {
+			return nil, fmt.Errorf("taproot tree script: %v", err)
+		}
+		leaves = append(leaves, TaprootTreeLeaf{Depth: depth, LeafVersion: leafVersion, Script: script})
+	}
+	if len(leaves) == 0 {
+		return nil, errors.New("empty taproot tree")
+	}
+	return leaves, nil
+}
+
+// checkPubKey verifies the length and prefix of a serialized ECDSA public key
+func checkPubKey(b []byte) error {
+	switch {
+	case len(b) == 33 && (b[0] == 0x02 || b[0] == 0x03):
+		return nil
+	case len(b) == 65 && b[0] == 0x04:
+		return nil
+	default:
+		return fmt.Errorf("invalid public key %s", hexKey(b))
+	}
+}
+
+// noKeyData rejects pairs whose key carries data after the type
+func noKeyData(kv pair) error {
+	if len(kv.keyData) != 0 {
+		return fmt.Errorf("unexpected key data for key type %#x", kv.keyType)
+	}
+	return nil
+}
+
+// fixedBytes returns the value of a keyless pair that must be exactly n bytes
+func fixedBytes(kv pair, n int) ([]byte, error) {
+	if err := noKeyData(kv); err != nil {
+		return nil, err
+	}
+	if len(kv.value) != n {
+		return nil, fmt.Errorf("value must be %d bytes, got %d", n, len(kv.value))
+	}
+	return kv.value, nil
+}
+
+// fixedUint32 decodes a keyless little-endian uint32 value
+func fixedUint32(kv pair) (uint32, error) {
+	b, err := fixedBytes(kv, 4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// optionalUint32 decodes a keyless little-endian uint32 value into a pointer
+func optionalUint32(kv pair) (*uint32, error) {
+	v, err := fixedUint32(kv)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,259 @@
+package psbt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Encode serializes the packet to binary
+func (p *Packet) Encode() ([]byte, error) {
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(magic)
+	p.Global.encode(&buf, len(p.Inputs), len(p.Outputs))
+	for _, in := range p.Inputs {
+		in.encode(&buf)
+	}
+	for _, out := range p.Outputs {
+		out.encode(&buf)
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeBase64 serializes the packet to the base64 form used by the PSBT RPCs
+func (p *Packet) EncodeBase64() (string, error) {
+	b, err := p.Encode()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// check verifies that the packet has the fields its version requires
+func (p *Packet) check() error {
+	g := &p.Global
+	switch g.Version {
+	case 0:
+		if g.UnsignedTx == nil {
+			return errors.New("psbt: version 0 packet needs an unsigned transaction")
+		}
+		if len(g.UnsignedTx.Inputs) != len(p.Inputs) || len(g.UnsignedTx.Outputs) != len(p.Outputs) {
+			return errors.New("psbt: input and output maps do not match the unsigned transaction")
+		}
+		for i, in := range g.UnsignedTx.Inputs {
+			if len(in.ScriptSig) > 0 || len(in.Witness) > 0 {
+				return fmt.Errorf("psbt: unsigned transaction input %d is not empty", i)
+			}
+		}
+	case 2:
+		if g.UnsignedTx != nil {
+			return errors.New("psbt: version 2 packet must not have an unsigned transaction")
+		}
+		if g.TxVersion == nil {
+			return errors.New("psbt: version 2 packet needs a transaction version")
+		}
+	default:
+		return fmt.Errorf("psbt: unsupported version %d", g.Version)
+	}
+	_, err := p.UnsignedTx()
+	return err
+}
+
+// encode writes the global map
+func (g *Global) encode(buf *bytes.Buffer, inputs, outputs int) {
+	if g.UnsignedTx != nil {
+		writePair(buf, GlobalUnsignedTx, nil, g.UnsignedTx.SerializeNoWitness())
+	}
+	for _, x := range g.Xpubs {
+		writePair(buf, GlobalXpub, x.ExtendedKey, encodeOrigin(x.Fingerprint, x.Path))
+	}
+	if g.Version >= 2 {
+		writePair(buf, GlobalTxVersion, nil, uint32Bytes(uint32(*g.TxVersion)))
+		if g.FallbackLocktime != nil {
+			writePair(buf, GlobalFallbackLocktime, nil, uint32Bytes(*g.FallbackLocktime))
+		}
+		writePair(buf, GlobalInputCount, nil, compactSizeBytes(uint64(inputs)))
+		writePair(buf, GlobalOutputCount, nil, compactSizeBytes(uint64(outputs)))
+		if g.TxModifiable != nil {
+			writePair(buf, GlobalTxModifiable, nil, []byte{*g.TxModifiable})
+		}
+	}
+	if g.Version != 0 {
+		writePair(buf, GlobalVersion, nil, uint32Bytes(g.Version))
+	}
+	writeUnknowns(buf, g.Unknowns)
+	buf.WriteByte(0x00)
+}
+
+// encode writes an input map
+func (in *Input) encode(buf *bytes.Buffer) {
+	if in.NonWitnessUTXO != nil {
+		writePair(buf, InNonWitnessUTXO, nil, in.NonWitnessUTXO.Serialize())
+	}
+	if in.WitnessUTXO != nil {
+		var out bytes.Buffer
+		in.WitnessUTXO.serialize(&out)
+		writePair(buf, InWitnessUTXO, nil, out.Bytes())
+	}
+	for _, sig := range in.PartialSigs {
+		writePair(buf, InPartialSig, sig.PubKey, sig.Signature)
+	}
+	if in.SighashType != nil {
+		writePair(buf, InSighashType, nil, uint32Bytes(*in.SighashType))
+	}
+	if in.RedeemScript != nil {
+		writePair(buf, InRedeemScript, nil, in.RedeemScript)
+	}
+	if in.WitnessScript != nil {
+		writePair(buf, InWitnessScript, nil, in.WitnessScript)
+	}
+	for _, d := range in.Bip32Derivations {
+		writePair(buf, InBip32Derivation, d.PubKey, encodeOrigin(d.Fingerprint, d.Path))
+	}
+	if in.FinalScriptSig != nil {
+		writePair(buf, InFinalScriptSig, nil, in.FinalScriptSig)
+	}
+	if in.FinalScriptWitness != nil {
+		var w bytes.Buffer
+		writeWitness(&w, in.FinalScriptWitness)
+		writePair(buf, InFinalScriptWitness, nil, w.Bytes())
+	}
+	for _, pre := range in.Ripemd160Preimages {
+		writePair(buf, InRipemd160, pre.Hash, pre.Preimage)
+	}
+	for _, pre := range in.Sha256Preimages {
+		writePair(buf, InSha256, pre.Hash, pre.Preimage)
+	}
+	for _, pre := range in.Hash160Preimages {
+		writePair(buf, InHash160, pre.Hash, pre.Preimage)
+	}
+	for _, pre := range in.Hash256Preimages {
+		writePair(buf, InHash256, pre.Hash, pre.Preimage)
+	}
+	if in.PreviousTxID != nil {
+		writePair(buf, InPreviousTxID, nil, in.PreviousTxID[:])
+	}
+	writeOptionalUint32(buf, InOutputIndex, in.OutputIndex)
+	writeOptionalUint32(buf, InSequence, in.Sequence)
+	writeOptionalUint32(buf, InRequiredTimeLocktime, in.RequiredTimeLocktime)
+	writeOptionalUint32(buf, InRequiredHeightLocktime, in.RequiredHeightLocktime)
+	if in.TaprootKeySig != nil {
+		writePair(buf, InTapKeySig, nil, in.TaprootKeySig)
+	}
+	for _, sig := range in.TaprootScriptSigs {
+		key := append(append([]byte{}, sig.XOnlyPubKey...), sig.LeafHash...)
+		writePair(buf, InTapScriptSig, key, sig.Signature)
+	}
+	for _, leaf := range in.TaprootLeafScripts {
+		value := append(append([]byte{}, leaf.Script...), leaf.LeafVersion)
+		writePair(buf, InTapLeafScript, leaf.ControlBlock, value)
+	}
+	for _, d := range in.TaprootBip32Derivs {
+		writePair(buf, InTapBip32Derivation, d.XOnlyPubKey, encodeTaprootOrigin(d))
+	}
+	if in.TaprootInternalKey != nil {
+		writePair(buf, InTapInternalKey, nil, in.TaprootInternalKey)
+	}
+	if in.TaprootMerkleRoot != nil {
+		writePair(buf, InTapMerkleRoot, nil, in.TaprootMerkleRoot)
+	}
+	writeUnknowns(buf, in.Unknowns)
+	buf.WriteByte(0x00)
+}
+
+// encode writes an output map
+func (out *Output) encode(buf *bytes.Buffer) {
+	if out.RedeemScript != nil {
+		writePair(buf, OutRedeemScript, nil, out.RedeemScript)
+	}
+	if out.WitnessScript != nil {
+		writePair(buf, OutWitnessScript, nil, out.WitnessScript)
+	}
+	for _, d := range out.Bip32Derivations {
+		writePair(buf, OutBip32Derivation, d.PubKey, encodeOrigin(d.Fingerprint, d.Path))
+	}
+	if out.Amount != nil {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(*out.Amount))
+		writePair(buf, OutAmount, nil, b[:])
+	}
+	if out.Script != nil {
+		writePair(buf, OutScript, nil, out.Script)
+	}
+	if out.TaprootInternalKey != nil {
+		writePair(buf, OutTapInternalKey, nil, out.TaprootInternalKey)
+	}
+	if len(out.TaprootTree) > 0 {
+		var tree bytes.Buffer
+		for _, leaf := range out.TaprootTree {
+			tree.WriteByte(leaf.Depth)
+			tree.WriteByte(leaf.LeafVersion)
+			writeVarBytes(&tree, leaf.Script)
+		}
+		writePair(buf, OutTapTree, nil, tree.Bytes())
+	}
+	for _, d := range out.TaprootBip32Derivs {
+		writePair(buf, OutTapBip32Derivation, d.XOnlyPubKey, encodeTaprootOrigin(d))
+	}
+	writeUnknowns(buf, out.Unknowns)
+	buf.WriteByte(0x00)
+}
+
+// writePair writes a key-value pair with the given key type and key data
+func writePair(buf *bytes.Buffer, keyType uint64, keyData, value []byte) {
+	key := append(compactSizeBytes(keyType), keyData...)
+	writeVarBytes(buf, key)
+	writeVarBytes(buf, value)
+}
+
+// writeOptionalUint32 writes a uint32 pair if v is set
+func writeOptionalUint32(buf *bytes.Buffer, keyType uint64, v *uint32) {
+	if v != nil {
+		writePair(buf, keyType, nil, uint32Bytes(*v))
+	}
+}
+
+// writeUnknowns writes pairs the package does not interpret exactly as they were read
+func writeUnknowns(buf *bytes.Buffer, unknowns []Unknown) {
+	for _, u := range unknowns {
+		writeVarBytes(buf, u.Key)
+		writeVarBytes(buf, u.Value)
+	}
+}
+
+// encodeOrigin serializes a fingerprint followed by the path steps
+func encodeOrigin(fp [4]byte, path []uint32) []byte {
+	b := make([]byte, 4, 4+4*len(path))
+	copy(b, fp[:])
+	for _, step := range path {
+		b = binary.LittleEndian.AppendUint32(b, step)
+	}
+	return b
+}
+
+// encodeTaprootOrigin serializes the leaf hashes and origin of a taproot derivation
+func encodeTaprootOrigin(d TaprootBip32Derivation) []byte {
+	b := compactSizeBytes(uint64(len(d.LeafHashes)))
+	for _, h := range d.LeafHashes {
+		b = append(b, h...)
+	}
+	return append(b, encodeOrigin(d.Fingerprint, d.Path)...)
+}
+
+// uint32Bytes returns v in little-endian byte order
+func uint32Bytes(v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
+
+// compactSizeBytes returns the compact size encoding of v
+func compactSizeBytes(v uint64) []byte {
+	var buf bytes.Buffer
+	writeCompactSize(&buf, v)
+	return buf.Bytes()
+}
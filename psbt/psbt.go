@@ -0,0 +1,313 @@
+// Package psbt encodes and decodes Partially Signed Bitcoin Transactions (BIP174 version 0
+// and BIP370 version 2) without a node, so PSBTs returned by the PSBT RPCs can be inspected
+// and edited locally. Unknown and proprietary fields are kept and written back unchanged.
+package psbt
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// magic is the "psbt" prefix followed by the 0xff separator
+var magic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// Global key types
+const (
+	GlobalUnsignedTx       = 0x00
+	GlobalXpub             = 0x01
+	GlobalTxVersion        = 0x02
+	GlobalFallbackLocktime = 0x03
+	GlobalInputCount       = 0x04
+	GlobalOutputCount      = 0x05
+	GlobalTxModifiable     = 0x06
+	GlobalVersion          = 0xfb
+	Proprietary            = 0xfc
+)
+
+// Input key types
+const (
+	InNonWitnessUTXO         = 0x00
+	InWitnessUTXO            = 0x01
+	InPartialSig             = 0x02
+	InSighashType            = 0x03
+	InRedeemScript           = 0x04
+	InWitnessScript          = 0x05
+	InBip32Derivation        = 0x06
+	InFinalScriptSig         = 0x07
+	InFinalScriptWitness     = 0x08
+	InRipemd160              = 0x0a
+	InSha256                 = 0x0b
+	InHash160                = 0x0c
+	InHash256                = 0x0d
+	InPreviousTxID           = 0x0e
+	InOutputIndex            = 0x0f
+	InSequence               = 0x10
+	InRequiredTimeLocktime   = 0x11
+	InRequiredHeightLocktime = 0x12
+	InTapKeySig              = 0x13
+	InTapScriptSig           = 0x14
+	InTapLeafScript          = 0x15
+	InTapBip32Derivation     = 0x16
+	InTapInternalKey         = 0x17
+	InTapMerkleRoot          = 0x18
+)
+
+// Output key types
+const (
+	OutRedeemScript       = 0x00
+	OutWitnessScript      = 0x01
+	OutBip32Derivation    = 0x02
+	OutAmount             = 0x03
+	OutScript             = 0x04
+	OutTapInternalKey     = 0x05
+	OutTapTree            = 0x06
+	OutTapBip32Derivation = 0x07
+)
+
+// Packet is a decoded PSBT
+type Packet struct {
+	Global  Global
+	Inputs  []*Input
+	Outputs []*Output
+}
+
+// Global holds the fields of the global map
+type Global struct {
+	UnsignedTx       *Tx // version 0 only
+	Xpubs            []Xpub
+	TxVersion        *int32  // version 2 only
+	FallbackLocktime *uint32 // version 2 only
+	TxModifiable     *uint8  // version 2 only
+	Version          uint32
+	Unknowns         []Unknown
+}
+
+// Input holds the fields of an input map
+type Input struct {
+	NonWitnessUTXO         *Tx
+	WitnessUTXO            *TxOut
+	PartialSigs            []PartialSig
+	SighashType            *uint32
+	RedeemScript           []byte
+	WitnessScript          []byte
+	Bip32Derivations       []Bip32Derivation
+	FinalScriptSig         []byte
+	FinalScriptWitness     [][]byte
+	Ripemd160Preimages     []Preimage
+	Sha256Preimages        []Preimage
+	Hash160Preimages       []Preimage
+	Hash256Preimages       []Preimage
+	PreviousTxID           *[32]byte // version 2 only, internal byte order
+	OutputIndex            *uint32   // version 2 only
+	Sequence               *uint32   // version 2 only
+	RequiredTimeLocktime   *uint32   // version 2 only
+	RequiredHeightLocktime *uint32   // version 2 only
+	TaprootKeySig          []byte
+	TaprootScriptSigs      []TaprootScriptSig
+	TaprootLeafScripts     []TaprootLeafScript
+	TaprootBip32Derivs     []TaprootBip32Derivation
+	TaprootInternalKey     []byte
+	TaprootMerkleRoot      []byte
+	Unknowns               []Unknown
+}
+
+// Output holds the fields of an output map
+type Output struct {
+	RedeemScript       []byte
+	WitnessScript      []byte
+	Bip32Derivations   []Bip32Derivation
+	Amount             *int64 // version 2 only, satoshis
+	Script             []byte // version 2 only
+	TaprootInternalKey []byte
+	TaprootTree        []TaprootTreeLeaf
+	TaprootBip32Derivs []TaprootBip32Derivation
+	Unknowns           []Unknown
+}
+
+// Xpub is a global extended public key with its origin
+type Xpub struct {
+	ExtendedKey []byte // 78-byte serialized extended public key
+	Fingerprint [4]byte
+	Path        []uint32
+}
+
+// PartialSig is a signature for a public key
+type PartialSig struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+// Bip32Derivation records the origin of a public key
+type Bip32Derivation struct {
+	PubKey      []byte
+	Fingerprint [4]byte
+	Path        []uint32
+}
+
+// Preimage is a hash preimage for a hashlock
+type Preimage struct {
+	Hash     []byte
+	Preimage []byte
+}
+
+// TaprootScriptSig is a signature for a key in a taproot leaf script
+type TaprootScriptSig struct {
+	XOnlyPubKey []byte
+	LeafHash    []byte
+	Signature   []byte
+}
+
+// TaprootLeafScript is a taproot leaf script with the control block proving it
+type TaprootLeafScript struct {
+	ControlBlock []byte
+	Script       []byte
+	LeafVersion  uint8
+}
+
+// TaprootBip32Derivation records the origin of an x-only key and the leaves it is used in
+type TaprootBip32Derivation struct {
+	XOnlyPubKey []byte
+	LeafHashes  [][]byte
+	Fingerprint [4]byte
+	Path        []uint32
+}
+
+// TaprootTreeLeaf is one leaf of an output taproot tree, in depth-first order
+type TaprootTreeLeaf struct {
+	Depth       uint8
+	LeafVersion uint8
+	Script      []byte
+}
+
+// Unknown is a key-value pair the package does not interpret, including proprietary fields
+// Key includes the key type prefix.
+type Unknown struct {
+	Key   []byte
+	Value []byte
+}
+
+// IsProprietary reports whether the pair is a proprietary (0xfc) field
+func (u Unknown) IsProprietary() bool {
+	return len(u.Key) > 0 && u.Key[0] == Proprietary
+}
+
+// FormatPath renders a BIP32 path such as m/84'/0'/0'/0/5
+func FormatPath(path []uint32) string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, step := range path {
+		sb.WriteByte('/')
+		if step >= 0x80000000 {
+			sb.WriteString(strconv.FormatUint(uint64(step-0x80000000), 10))
+			sb.WriteByte('\'')
+		} else {
+			sb.WriteString(strconv.FormatUint(uint64(step), 10))
+		}
+	}
+	return sb.String()
+}
+
+// PSBTVersion returns the version of the packet (0 when the version field is absent)
+func (p *Packet) PSBTVersion() uint32 {
+	return p.Global.Version
+}
+
+// UnsignedTx returns the unsigned transaction described by the packet
+// For version 2 packets it is assembled from the per-input and per-output fields.
+func (p *Packet) UnsignedTx() (*Tx, error) {
+	if p.Global.Version < 2 {
+		if p.Global.UnsignedTx == nil {
+			return nil, fmt.Errorf("psbt: missing unsigned transaction")
+		}
+		return p.Global.UnsignedTx, nil
+	}
+
+	if p.Global.TxVersion == nil {
+		return nil, fmt.Errorf("psbt: missing tx version")
+	}
+	tx := &Tx{Version: *p.Global.TxVersion}
+	for i, in := range p.Inputs {
+		if in.PreviousTxID == nil || in.OutputIndex == nil {
+			return nil, fmt.Errorf("psbt: input %d is missing its outpoint", i)
+		}
+		seq := uint32(0xffffffff)
+		if in.Sequence != nil {
+			seq = *in.Sequence
+		}
+		tx.Inputs = append(tx.Inputs, TxIn{PrevTxID: *in.PreviousTxID, PrevIndex: *in.OutputIndex, Sequence: seq})
+	}
+	for i, out := range p.Outputs {
+		if out.Amount == nil || out.Script == nil {
+			return nil, fmt.Errorf("psbt: output %d is missing its amount or script", i)
+		}
+		tx.Outputs = append(tx.Outputs, TxOut{Value: *out.Amount, PkScript: out.Script})
+	}
+	lockTime, err := p.lockTime()
+	if err != nil {
+		return nil, err
+	}
+	tx.LockTime = lockTime
+	return tx, nil
+}
+
+// lockTime determines the locktime of a version 2 packet as described in BIP370
+func (p *Packet) lockTime() (uint32, error) {
+	var (
+		hasTime, hasHeight     bool
+		allowTime, allowHeight = true, true
+		maxTime, maxHeight     uint32
+	)
+	for _, in := range p.Inputs {
+		if in.RequiredTimeLocktime != nil {
+			hasTime = true
+			maxTime = max(maxTime, *in.RequiredTimeLocktime)
+		} else if in.RequiredHeightLocktime != nil {
+			allowTime = false
+		}
+		if in.RequiredHeightLocktime != nil {
+			hasHeight = true
+			maxHeight = max(maxHeight, *in.RequiredHeightLocktime)
+		} else if in.RequiredTimeLocktime != nil {
+			allowHeight = false
+		}
+	}
+
+	switch {
+	case hasHeight && allowHeight:
+		// Height is preferred when both are possible
+		return maxHeight, nil
+	case hasTime && allowTime:
+		return maxTime, nil
+	case hasTime || hasHeight:
+		return 0, fmt.Errorf("psbt: inputs require incompatible locktime types")
+	case p.Global.FallbackLocktime != nil:
+		return *p.Global.FallbackLocktime, nil
+	default:
+		return 0, nil
+	}
+}
+
+// PreviousOutPoint returns the outpoint spent by input i as "txid:vout"
+func (p *Packet) PreviousOutPoint(i int) (string, error) {
+	tx, err := p.UnsignedTx()
+	if err != nil {
+		return "", err
+	}
+	if i < 0 || i >= len(tx.Inputs) {
+		return "", fmt.Errorf("psbt: input %d out of range", i)
+	}
+	in := tx.Inputs[i]
+	return fmt.Sprintf("%s:%d", hashToString(in.PrevTxID), in.PrevIndex), nil
+}
+
+// String renders a short description of the packet for logging
+func (p *Packet) String() string {
+	return fmt.Sprintf("psbt v%d (%d inputs, %d outputs)", p.Global.Version, len(p.Inputs), len(p.Outputs))
+}
+
+// hexKey renders key data for error messages
+func hexKey(b []byte) string {
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,376 @@
+package psbt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxVectorSize bounds the length prefixes accepted while decoding, to avoid huge allocations on bad input
+const maxVectorSize = 4_000_000
+
+// Tx is a bitcoin transaction in network serialization
+type Tx struct {
+	Version  int32
+	Inputs   []TxIn
+	Outputs  []TxOut
+	LockTime uint32
+}
+
+// TxIn is a transaction input
+type TxIn struct {
+	PrevTxID  [32]byte // previous transaction hash in internal (little-endian) byte order
+	PrevIndex uint32
+	ScriptSig []byte
+	Sequence  uint32
+	Witness   [][]byte
+}
+
+// TxOut is a transaction output
+type TxOut struct {
+	Value    int64 // amount in satoshis
+	PkScript []byte
+}
+
+// HasWitness reports whether any input carries witness data
+func (tx *Tx) HasWitness() bool {
+	for _, in := range tx.Inputs {
+		if len(in.Witness) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Serialize encodes the transaction, including witness data if any input has some
+func (tx *Tx) Serialize() []byte {
+	var buf bytes.Buffer
+	tx.serialize(&buf, tx.HasWitness())
+	return buf.Bytes()
+}
+
+// SerializeNoWitness encodes the transaction without witness data
+func (tx *Tx) SerializeNoWitness() []byte {
+	var buf bytes.Buffer
+	tx.serialize(&buf, false)
+	return buf.Bytes()
+}
+
+// TxID returns the transaction id as a hex string in the usual reversed byte order
+func (tx *Tx) TxID() string {
+	return hashToString(doubleSHA256(tx.SerializeNoWitness()))
+}
+
+// WTxID returns the witness transaction id as a hex string in the usual reversed byte order
+func (tx *Tx) WTxID() string {
+	return hashToString(doubleSHA256(tx.Serialize()))
+}
+
+// serialize writes the transaction to buf
+func (tx *Tx) serialize(buf *bytes.Buffer, witness bool) {
+	writeUint32(buf, uint32(tx.Version))
+	if witness {
+		buf.Write([]byte{0x00, 0x01})
+	}
+	writeCompactSize(buf, uint64(len(tx.Inputs)))
+	for _, in := range tx.Inputs {
+		buf.Write(in.PrevTxID[:])
+		writeUint32(buf, in.PrevIndex)
+		writeVarBytes(buf, in.ScriptSig)
+		writeUint32(buf, in.Sequence)
+	}
+	writeCompactSize(buf, uint64(len(tx.Outputs)))
+	for _, out := range tx.Outputs {
+		out.serialize(buf)
+	}
+	if witness {
+		for _, in := range tx.Inputs {
+			writeWitness(buf, in.Witness)
+		}
+	}
+	writeUint32(buf, tx.LockTime)
+}
+
+// serialize writes the output to buf
+func (out *TxOut) serialize(buf *bytes.Buffer) {
+	writeUint64(buf, uint64(out.Value))
+	writeVarBytes(buf, out.PkScript)
+}
+
+// DecodeTx parses a transaction, accepting both the legacy and the segwit serialization
+func DecodeTx(b []byte) (*Tx, error) {
+	return decodeTx(b, true)
+}
+
+// DecodeTxHex parses a hex encoded transaction
+func DecodeTxHex(s string) (*Tx, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hex: %v", err)
+	}
+	return DecodeTx(b)
+}
+
+// decodeTx parses a complete transaction from b
+func decodeTx(b []byte, allowWitness bool) (*Tx, error) {
+	r := bytes.NewReader(b)
+	tx, err := readTx(r, allowWitness)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after transaction", r.Len())
+	}
+	return tx, nil
+}
+
+// readTx reads a transaction from r
+func readTx(r *bytes.Reader, allowWitness bool) (*Tx, error) {
+	tx := &Tx{}
+	version, err := readUint32(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading version: %v", err)
+	}
+	tx.Version = int32(version)
+
+	count, err := readCompactSize(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading input count: %v", err)
+	}
+
+	// A zero input count followed by a 0x01 flag marks the segwit serialization
+	witness := false
+	if count == 0 && allowWitness {
+		flag, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("reading segwit flag: %v", err)
+		}
+		if flag != 0x01 {
+			return nil, fmt.Errorf("unexpected segwit flag %#x", flag)
+		}
+		witness = true
+		if count, err = readCompactSize(r); err != nil {
+			return nil, fmt.Errorf("reading input count: %v", err)
+		}
+	}
+
+	// An input takes at least 41 bytes and an output at least 9
+	if count > maxVectorSize/41 || count > uint64(r.Len())/41 {
+		return nil, fmt.Errorf("too many inputs: %d", count)
+	}
+	tx.Inputs = make([]TxIn, count)
+	for i := range tx.Inputs {
+		in := &tx.Inputs[i]
+		if _, err := io.ReadFull(r, in.PrevTxID[:]); err != nil {
+			return nil, fmt.Errorf("reading input %d outpoint: %v", i, err)
+		}
+		if in.PrevIndex, err = readUint32(r); err != nil {
+			return nil, fmt.Errorf("reading input %d outpoint: %v", i, err)
+		}
+		if in.ScriptSig, err = readVarBytes(r); err != nil {
+			return nil, fmt.Errorf("reading input %d scriptSig: %v", i, err)
+		}
+		if in.Sequence, err = readUint32(r); err != nil {
+			return nil, fmt.Errorf("reading input %d sequence: %v", i, err)
+		}
+	}
+
+	if count, err = readCompactSize(r); err != nil {
+		return nil, fmt.Errorf("reading output count: %v", err)
+	}
+	if count > maxVectorSize/9 || count > uint64(r.Len())/9 {
+		return nil, fmt.Errorf("too many outputs: %d", count)
+	}
+	tx.Outputs = make([]TxOut, count)
+	for i := range tx.Outputs {
+		out, err := readTxOut(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading output %d: %v", i, err)
+		}
+		tx.Outputs[i] = *out
+	}
+
+	if witness {
+		for i := range tx.Inputs {
+			if tx.Inputs[i].Witness, err = readWitness(r); err != nil {
+				return nil, fmt.Errorf("reading input %d witness: %v", i, err)
+			}
+		}
+	}
+
+	if tx.LockTime, err = readUint32(r); err != nil {
+		return nil, fmt.Errorf("reading locktime: %v", err)
+	}
+	return tx, nil
+}
+
+// readTxOut reads a single transaction output
+func readTxOut(r *bytes.Reader) (*TxOut, error) {
+	value, err := readUint64(r)
+	if err != nil {
+		return nil, err
+	}
+	script, err := readVarBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	return &TxOut{Value: int64(value), PkScript: script}, nil
+}
+
+// readWitness reads a witness stack
+func readWitness(r *bytes.Reader) ([][]byte, error) {
+	n, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	// Every item takes at least its one byte length prefix
+	if n > maxVectorSize || n > uint64(r.Len()) {
+		return nil, fmt.Errorf("witness stack too large: %d", n)
+	}
+	stack := make([][]byte, n)
+	for i := range stack {
+		if stack[i], err = readVarBytes(r); err != nil {
+			return nil, err
+		}
+	}
+	return stack, nil
+}
+
+// writeWitness writes a witness stack
+func writeWitness(buf *bytes.Buffer, stack [][]byte) {
+	writeCompactSize(buf, uint64(len(stack)))
+	for _, item := range stack {
+		writeVarBytes(buf, item)
+	}
+}
+
+// doubleSHA256 returns SHA256(SHA256(b))
+func doubleSHA256(b []byte) [32]byte {
+	first := sha256.Sum256(b)
+	return sha256.Sum256(first[:])
+}
+
+// hashToString renders a hash in the reversed byte order used by RPC and block explorers
+func hashToString(h [32]byte) string {
+	for i, j := 0, len(h)-1; i < j; i, j = i+1, j-1 {
+		h[i], h[j] = h[j], h[i]
+	}
+	return hex.EncodeToString(h[:])
+}
+
+// readCompactSize reads a bitcoin variable length integer, rejecting non-canonical encodings
+func readCompactSize(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch prefix {
+	case 0xfd:
+		var b [2]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, err
+		}
+		v := uint64(binary.LittleEndian.Uint16(b[:]))
+		if v < 0xfd {
+			return 0, errors.New("non-canonical compact size")
+		}
+		return v, nil
+	case 0xfe:
+		v, err := readUint32(r)
+		if err != nil {
+			return 0, err
+		}
+		if v <= 0xffff {
+			return 0, errors.New("non-canonical compact size")
+		}
+		return uint64(v), nil
+	case 0xff:
+		v, err := readUint64(r)
+		if err != nil {
+			return 0, err
+		}
+		if v <= 0xffffffff {
+			return 0, errors.New("non-canonical compact size")
+		}
+		return v, nil
+	default:
+		return uint64(prefix), nil
+	}
+}
+
+// writeCompactSize writes a bitcoin variable length integer
+func writeCompactSize(buf *bytes.Buffer, v uint64) {
+	switch {
+	case v < 0xfd:
+		buf.WriteByte(byte(v))
+	case v <= 0xffff:
+		buf.WriteByte(0xfd)
+		var b [2]byte
+		binary.LittleEndian.PutUint16(b[:], uint16(v))
+		buf.Write(b[:])
+	case v <= 0xffffffff:
+		buf.WriteByte(0xfe)
+		writeUint32(buf, uint32(v))
+	default:
+		buf.WriteByte(0xff)
+		writeUint64(buf, v)
+	}
+}
+
+// readVarBytes reads a compact size length followed by that many bytes
+func readVarBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) || n > maxVectorSize {
+		return nil, fmt.Errorf("length %d exceeds remaining data", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// writeVarBytes writes a compact size length followed by b
+func writeVarBytes(buf *bytes.Buffer, b []byte) {
+	writeCompactSize(buf, uint64(len(b)))
+	buf.Write(b)
+}
+
+// readUint32 reads a little-endian uint32
+func readUint32(r *bytes.Reader) (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+// readUint64 reads a little-endian uint64
+func readUint64(r *bytes.Reader) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+// writeUint32 writes a little-endian uint32
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+// writeUint64 writes a little-endian uint64
+func writeUint64(buf *bytes.Buffer, v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	buf.Write(b[:])
+}
@@ -0,0 +1,36 @@
+package btcrpc
+
+import (
+	"fmt"
+
+	"github.com/koinvote/btcrpc/merkle"
+)
+
+// VerifyTxOutProofOffline checks a gettxoutproof proof against a trusted block header without a node
+// header is typically a GetBlockHeader result stored alongside the proof. The proof must be for
+// that block and its partial merkle tree must hash to the header's merkle root. Returns the
+// txids the proof commits to.
+func VerifyTxOutProofOffline(proof string, header *GetBlockHeaderResponse) ([]string, error) {
+	block, err := merkle.ParseHex(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	if hash := block.Header.BlockHash(); hash != header.Hash {
+		return nil, fmt.Errorf("proof is for block %s, not %s", hash, header.Hash)
+	}
+	if root := block.Header.MerkleRootHash(); root != header.MerkleRoot {
+		return nil, fmt.Errorf("proof merkle root %s does not match header merkle root %s", root, header.MerkleRoot)
+	}
+
+	matches, err := block.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	txids := make([]string, len(matches))
+	for i, match := range matches {
+		txids[i] = match.TxID
+	}
+	return txids, nil
+}
@@ -1,6 +1,11 @@
 package btcrpc
 
-import "encoding/json"
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
 
 // RPCRequest 代表一個 JSON-RPC 請求 / represents a JSON-RPC request
 type RPCRequest struct {
@@ -23,6 +28,23 @@ type RPCError struct {
 	Message string `json:"message"` // 錯誤描述信息 / Error description message
 }
 
+// Error 實現 error 接口，可用 errors.As 取得錯誤代碼 / implements the error interface so the code can be read with errors.As
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
+// Bitcoin Core RPC 錯誤代碼 / Bitcoin Core RPC error codes
+const (
+	RPCErrMisc                 = -1  // 一般錯誤 / General error
+	RPCErrInvalidAddressOrKey  = -5  // 無效的地址、密鑰或找不到交易 / Invalid address or key, or transaction not found
+	RPCErrInvalidParameter     = -8  // 無效的參數 / Invalid parameter
+	RPCErrWalletNotFound       = -18 // 找不到錢包 / Invalid wallet specified
+	RPCErrVerify               = -25 // 交易或區塊驗證失敗 / General error during transaction or block submission
+	RPCErrVerifyRejected       = -26 // 交易被網絡規則拒絕 / Transaction or block was rejected by network rules
+	RPCErrVerifyAlreadyInChain = -27 // 交易已在區塊鏈中 / Transaction already in chain
+	RPCErrInWarmup             = -28 // 節點仍在啟動中 / Client still warming up
+)
+
 // BlockchainInfo 代表 getblockchaininfo 的回應數據 / represents the response from getblockchaininfo
 type BlockchainInfo struct {
 	Chain                string  `json:"chain"`                // 區塊鏈網絡名稱（main、test、regtest）/ Blockchain network name (main, test, regtest)
@@ -101,11 +123,13 @@ type Transaction struct {
 	BlockHash         string   `json:"blockhash,omitempty"`          // 包含此交易的區塊哈希 / Hash of block containing this transaction
 	BlockIndex        int      `json:"blockindex,omitempty"`         // 交易在區塊中的索引 / Transaction index in the block
 	BlockTime         int64    `json:"blocktime,omitempty"`          // 區塊時間戳 / Block timestamp
+	BlockHeight       int      `json:"blockheight,omitempty"`        // 包含此交易的區塊高度 / Height of block containing this transaction
 	TxID              string   `json:"txid"`                         // 交易 ID / Transaction ID
 	WalletConflicts   []string `json:"walletconflicts"`              // 與此交易衝突的交易列表 / List of conflicting transactions
 	Time              int64    `json:"time"`                         // 交易時間戳 / Transaction timestamp
 	TimeReceived      int64    `json:"timereceived"`                 // 接收到交易的時間戳 / Time when transaction was received
 	BIP125Replaceable string   `json:"bip125-replaceable,omitempty"` // 是否支持 BIP125 替換 / Whether BIP125 replacement is enabled
+	Abandoned         bool     `json:"abandoned,omitempty"`          // 交易是否被放棄（僅發送類別）/ Whether the transaction is abandoned (send category only)
 	Comment           string   `json:"comment,omitempty"`            // 交易註釋 / Transaction comment
 	To                string   `json:"to,omitempty"`                 // 收款方註釋 / Recipient comment
 }
@@ -113,6 +137,13 @@ type Transaction struct {
 // ListTransactionsResponse 代表 listtransactions 的回應，返回交易列表 / represents the response from listtransactions
 type ListTransactionsResponse []Transaction
 
+// ListSinceBlockResponse 代表 listsinceblock 的回應數據 / represents the response from listsinceblock
+type ListSinceBlockResponse struct {
+	Transactions []Transaction `json:"transactions"`      // 自指定區塊以來的錢包交易 / Wallet transactions since the given block
+	Removed      []Transaction `json:"removed,omitempty"` // 因重組而移除的交易 / Transactions removed by a reorg
+	LastBlock    string        `json:"lastblock"`         // 下次查詢使用的區塊哈希（目標確認數處）/ Block hash to use in the next call (at the target_confirmations depth)
+}
+
 // ValidateAddressResponse 代表 validateaddress 的回應數據 / represents the response from validateaddress
 type ValidateAddressResponse struct {
 	IsValid        bool   `json:"isvalid"`                   // 地址是否有效 / Whether the address is valid
@@ -200,6 +231,221 @@ type GetBlockResponse struct {
 	Tx                []string `json:"tx"`                          // 區塊中的交易 ID 列表 / List of transaction IDs in block
 }
 
+// GetBlockHeaderResponse 代表 getblockheader 的回應數據 / represents the response from getblockheader
+type GetBlockHeaderResponse struct {
+	Hash              string  `json:"hash"`                        // 區塊哈希 / Block hash
+	Confirmations     int     `json:"confirmations"`               // 確認次數，不在主鏈上時為 -1 / Number of confirmations, -1 if not on the main chain
+	Height            int     `json:"height"`                      // 區塊高度 / Block height
+	Version           int     `json:"version"`                     // 區塊版本 / Block version
+	VersionHex        string  `json:"versionHex"`                  // 區塊版本（十六進制）/ Block version (hex)
+	MerkleRoot        string  `json:"merkleroot"`                  // 默克爾樹根 / Merkle tree root
+	Time              int64   `json:"time"`                        // 區塊時間戳 / Block timestamp
+	MedianTime        int64   `json:"mediantime"`                  // 中位時間 / Median time
+	Nonce             uint32  `json:"nonce"`                       // 隨機數 / Nonce
+	Bits              string  `json:"bits"`                        // 難度目標（十六進制）/ Difficulty target (hex)
+	Target            string  `json:"target,omitempty"`            // 難度目標（完整十六進制）/ Difficulty target (full hex)
+	Difficulty        float64 `json:"difficulty"`                  // 挖礦難度 / Mining difficulty
+	ChainWork         string  `json:"chainwork"`                   // 區塊鏈總工作量（十六進制）/ Total chainwork (hex)
+	NTx               int     `json:"nTx"`                         // 區塊中的交易數量 / Number of transactions in block
+	PreviousBlockHash string  `json:"previousblockhash,omitempty"` // 前一個區塊的哈希 / Hash of previous block
+	NextBlockHash     string  `json:"nextblockhash,omitempty"`     // 下一個區塊的哈希 / Hash of next block
+}
+
+// WaitForBlockResponse 代表 waitfornewblock、waitforblock 和 waitforblockheight 的回應數據 / represents the response from waitfornewblock, waitforblock and waitforblockheight
+type WaitForBlockResponse struct {
+	Hash   string `json:"hash"`   // 等待結束時的最佳區塊哈希 / Best block hash when the wait ended
+	Height int    `json:"height"` // 等待結束時的最佳區塊高度 / Best block height when the wait ended
+}
+
+// ChainTip 代表 getchaintips 返回的鏈端 / represents a chain tip returned by getchaintips
+type ChainTip struct {
+	Height    int            `json:"height"`    // 鏈端高度 / Height of the chain tip
+	Hash      string         `json:"hash"`      // 鏈端區塊哈希 / Block hash of the tip
+	BranchLen int            `json:"branchlen"` // 分支長度，主鏈為 0 / Length of the branch, 0 for the main chain
+	Status    ChainTipStatus `json:"status"`    // 鏈端狀態 / Status of the chain tip
+}
+
+// ChainTipStatus 代表鏈端的狀態 / represents the status of a chain tip
+type ChainTipStatus string
+
+// 鏈端狀態 / Chain tip statuses
+const (
+	ChainTipActive       ChainTipStatus = "active"        // 主鏈的鏈端 / Tip of the main chain
+	ChainTipValidFork    ChainTipStatus = "valid-fork"    // 已完全驗證但不在主鏈上 / Fully validated but not part of the main chain
+	ChainTipValidHeaders ChainTipStatus = "valid-headers" // 區塊已下載但未完全驗證 / Blocks available but not fully validated
+	ChainTipHeadersOnly  ChainTipStatus = "headers-only"  // 僅有區塊頭，區塊未下載 / Only headers known, blocks not downloaded
+	ChainTipInvalid      ChainTipStatus = "invalid"       // 分支包含無效區塊 / Branch contains an invalid block
+)
+
+// GetBlockStatsResponse 代表 getblockstats 的回應數據，未請求的統計項為零值 / represents the response from getblockstats, stats not requested are left zero
+type GetBlockStatsResponse struct {
+	AvgFee             int64   `json:"avgfee"`               // 平均手續費（聰）/ Average fee in the block (satoshis)
+	AvgFeeRate         int64   `json:"avgfeerate"`           // 平均手續費率（聰/vB）/ Average fee rate (sat/vB)
+	AvgTxSize          int64   `json:"avgtxsize"`            // 平均交易大小（字節）/ Average transaction size (bytes)
+	BlockHash          string  `json:"blockhash"`            // 區塊哈希 / Block hash
+	FeeRatePercentiles []int64 `json:"feerate_percentiles"`  // 按權重的第 10、25、50、75、90 百分位手續費率（聰/vB）/ 10th, 25th, 50th, 75th and 90th weight-based fee rate percentiles (sat/vB)
+	Height             int     `json:"height"`               // 區塊高度 / Block height
+	Ins                int     `json:"ins"`                  // 輸入數量（不含 coinbase）/ Number of inputs (excluding coinbase)
+	MaxFee             int64   `json:"maxfee"`               // 最高手續費（聰）/ Maximum fee (satoshis)
+	MaxFeeRate         int64   `json:"maxfeerate"`           // 最高手續費率（聰/vB）/ Maximum fee rate (sat/vB)
+	MaxTxSize          int64   `json:"maxtxsize"`            // 最大交易大小 / Maximum transaction size
+	MedianFee          int64   `json:"medianfee"`            // 手續費中位數（聰）/ Median fee (satoshis)
+	MedianTime         int64   `json:"mediantime"`           // 區塊中位時間 / Block median time past
+	MedianTxSize       int64   `json:"mediantxsize"`         // 交易大小中位數 / Median transaction size
+	MinFee             int64   `json:"minfee"`               // 最低手續費（聰）/ Minimum fee (satoshis)
+	MinFeeRate         int64   `json:"minfeerate"`           // 最低手續費率（聰/vB）/ Minimum fee rate (sat/vB)
+	MinTxSize          int64   `json:"mintxsize"`            // 最小交易大小 / Minimum transaction size
+	Outs               int     `json:"outs"`                 // 輸出數量 / Number of outputs
+	Subsidy            int64   `json:"subsidy"`              // 區塊補貼（聰）/ Block subsidy (satoshis)
+	SwTotalSize        int64   `json:"swtotal_size"`         // 隔離見證交易總大小 / Total size of segwit transactions
+	SwTotalWeight      int64   `json:"swtotal_weight"`       // 隔離見證交易總權重 / Total weight of segwit transactions
+	SwTxs              int     `json:"swtxs"`                // 隔離見證交易數量 / Number of segwit transactions
+	Time               int64   `json:"time"`                 // 區塊時間 / Block time
+	TotalOut           int64   `json:"total_out"`            // 輸出總額，不含 coinbase（聰）/ Total output amount excluding coinbase (satoshis)
+	TotalSize          int64   `json:"total_size"`           // 交易總大小，不含 coinbase / Total size of transactions excluding coinbase
+	TotalWeight        int64   `json:"total_weight"`         // 交易總權重，不含 coinbase / Total weight of transactions excluding coinbase
+	TotalFee           int64   `json:"totalfee"`             // 手續費總額（聰）/ Total fees (satoshis)
+	Txs                int     `json:"txs"`                  // 交易數量，含 coinbase / Number of transactions including coinbase
+	UTXOIncrease       int64   `json:"utxo_increase"`        // UTXO 數量的增加 / Increase in the number of UTXOs
+	UTXOSizeInc        int64   `json:"utxo_size_inc"`        // UTXO 集大小的增加 / Increase in the UTXO set size
+	UTXOIncreaseActual *int64  `json:"utxo_increase_actual"` // 不含不可花費輸出的 UTXO 增加 / UTXO increase excluding unspendable outputs
+	UTXOSizeIncActual  *int64  `json:"utxo_size_inc_actual"` // 不含不可花費輸出的 UTXO 集大小增加 / UTXO set size increase excluding unspendable outputs
+}
+
+// GetChainTxStatsResponse 代表 getchaintxstats 的回應數據 / represents the response from getchaintxstats
+type GetChainTxStatsResponse struct {
+	Time                   int64   `json:"time"`                      // 窗口最後區塊的時間 / Time of the final block in the window
+	TxCount                int64   `json:"txcount,omitempty"`         // 到該區塊為止的交易總數 / Total number of transactions up to that block
+	WindowFinalBlockHash   string  `json:"window_final_block_hash"`   // 窗口最後區塊的哈希 / Hash of the final block in the window
+	WindowFinalBlockHeight int     `json:"window_final_block_height"` // 窗口最後區塊的高度 / Height of the final block in the window
+	WindowBlockCount       int     `json:"window_block_count"`        // 窗口中的區塊數 / Number of blocks in the window
+	WindowInterval         int64   `json:"window_interval,omitempty"` // 窗口經過的秒數 / Elapsed time in the window in seconds
+	WindowTxCount          int64   `json:"window_tx_count,omitempty"` // 窗口中的交易數 / Number of transactions in the window
+	TxRate                 float64 `json:"txrate,omitempty"`          // 窗口中每秒平均交易數 / Average transactions per second in the window
+}
+
+// GetTxOutResponse 代表 gettxout 的回應數據 / represents the response from gettxout
+type GetTxOutResponse struct {
+	BestBlock     string                     `json:"bestblock"`     // 當前最佳區塊哈希 / Hash of the current best block
+	Confirmations int                        `json:"confirmations"` // 確認數，內存池中為 0 / Number of confirmations, 0 in the mempool
+	Value         float64                    `json:"value"`         // 輸出金額（BTC）/ Output amount (BTC)
+	ScriptPubKey  RawTransactionScriptPubKey `json:"scriptPubKey"`  // 腳本公鑰 / Script public key
+	Coinbase      bool                       `json:"coinbase"`      // 是否為 coinbase 輸出 / Whether this is a coinbase output
+}
+
+// GetTxOutSetInfoResponse 代表 gettxoutsetinfo 的回應數據 / represents the response from gettxoutsetinfo
+type GetTxOutSetInfoResponse struct {
+	Height                 int            `json:"height"`                      // 區塊高度 / Block height
+	BestBlock              string         `json:"bestblock"`                   // 區塊哈希 / Block hash
+	TxOuts                 int64          `json:"txouts"`                      // 未花費輸出數量 / Number of unspent outputs
+	BogoSize               int64          `json:"bogosize"`                    // UTXO 集大小的估算值 / Database-independent size metric of the UTXO set
+	HashSerialized3        string         `json:"hash_serialized_3,omitempty"` // hash_serialized_3 類型的 UTXO 集哈希 / UTXO set hash of type hash_serialized_3
+	MuHash                 string         `json:"muhash,omitempty"`            // muhash 類型的 UTXO 集哈希 / UTXO set hash of type muhash
+	Transactions           int64          `json:"transactions,omitempty"`      // 有未花費輸出的交易數（不使用索引時）/ Number of transactions with unspent outputs (without index)
+	DiskSize               int64          `json:"disk_size,omitempty"`         // 磁盤上 UTXO 集的大小（不使用索引時）/ Size of the UTXO set on disk (without index)
+	TotalAmount            float64        `json:"total_amount"`                // 未花費總額（BTC）/ Total amount of unspent coins (BTC)
+	TotalUnspendableAmount float64        `json:"total_unspendable_amount"`    // 不可花費的總額（BTC，使用索引時）/ Total unspendable amount (BTC, with index)
+	BlockInfo              *TxOutSetBlock `json:"block_info,omitempty"`        // 該區塊的統計（使用索引時）/ Statistics of the block (with index)
+}
+
+// gettxoutsetinfo 的 UTXO 集哈希類型 / UTXO set hash types for gettxoutsetinfo
+const (
+	TxOutSetHashSerialized3 = "hash_serialized_3" // 默認的序列化哈希 / Default serialized hash
+	TxOutSetHashMuHash      = "muhash"            // MuHash，可增量計算 / MuHash, computed incrementally
+	TxOutSetHashNone        = "none"              // 不計算哈希 / No hash
+)
+
+// TxOutSetBlock 代表 gettxoutsetinfo 使用索引時的區塊統計 / represents the per-block statistics of gettxoutsetinfo with the index
+type TxOutSetBlock struct {
+	PrevoutSpent         float64              `json:"prevout_spent"`           // 花費的輸出總額（BTC）/ Total amount of spent outputs (BTC)
+	Coinbase             float64              `json:"coinbase"`                // coinbase 輸出總額（BTC）/ Coinbase output amount (BTC)
+	NewOutputsExCoinbase float64              `json:"new_outputs_ex_coinbase"` // 非 coinbase 新輸出總額（BTC）/ Amount of new non-coinbase outputs (BTC)
+	Unspendable          float64              `json:"unspendable"`             // 不可花費的金額（BTC）/ Unspendable amount (BTC)
+	Unspendables         TxOutSetUnspendables `json:"unspendables"`            // 不可花費金額的明細 / Breakdown of unspendable amounts
+}
+
+// TxOutSetUnspendables 代表不可花費金額的明細 / represents the breakdown of unspendable amounts
+type TxOutSetUnspendables struct {
+	GenesisBlock     float64 `json:"genesis_block"`     // 創世區塊輸出 / Genesis block output
+	BIP30            float64 `json:"bip30"`             // 被 BIP30 覆蓋的交易 / Transactions overwritten by BIP30
+	Scripts          float64 `json:"scripts"`           // 不可花費的腳本 / Unspendable scripts
+	UnclaimedRewards float64 `json:"unclaimed_rewards"` // 未領取的區塊獎勵 / Unclaimed block rewards
+}
+
+// ScanObject 代表 scantxoutset 的掃描對象 / represents a scan object for scantxoutset
+type ScanObject struct {
+	Desc  string  // 輸出描述符 / Output descriptor
+	Range *[2]int // 範圍描述符的索引範圍，nil 使用節點默認值 / Index range for ranged descriptors, nil for the node default
+}
+
+// MarshalJSON encodes the scan object as a plain descriptor string or as a {desc, range} object
+func (o ScanObject) MarshalJSON() ([]byte, error) {
+	if o.Range == nil {
+		return json.Marshal(o.Desc)
+	}
+	return json.Marshal(struct {
+		Desc  string `json:"desc"`
+		Range [2]int `json:"range"`
+	}{o.Desc, *o.Range})
+}
+
+// ScanAddress returns a scan object for the outputs paying to address
+func ScanAddress(address string) ScanObject {
+	return ScanObject{Desc: "addr(" + address + ")"}
+}
+
+// ScanDescriptor returns a scan object for desc, deriving indexes start to end for ranged descriptors
+func ScanDescriptor(desc string, start, end int) ScanObject {
+	return ScanObject{Desc: desc, Range: &[2]int{start, end}}
+}
+
+// ScanTxOutSetResponse 代表 scantxoutset start 的回應數據 / represents the response from scantxoutset start
+type ScanTxOutSetResponse struct {
+	Success     bool             `json:"success"`      // 掃描是否完成（中止時為 false）/ Whether the scan completed (false when aborted)
+	TxOuts      int64            `json:"txouts"`       // 掃描的未花費輸出數量 / Number of unspent outputs scanned
+	Height      int              `json:"height"`       // 掃描時的區塊高度 / Block height at which the scan was done
+	BestBlock   string           `json:"bestblock"`    // 掃描時的最佳區塊哈希 / Hash of the best block at the time of the scan
+	Unspents    []ScannedUnspent `json:"unspents"`     // 匹配的未花費輸出 / Matching unspent outputs
+	TotalAmount float64          `json:"total_amount"` // 匹配輸出的總額（BTC）/ Total amount of matching outputs (BTC)
+}
+
+// ScannedUnspent 代表 scantxoutset 找到的未花費輸出 / represents an unspent output found by scantxoutset
+type ScannedUnspent struct {
+	TxID          string  `json:"txid"`          // 交易 ID / Transaction ID
+	Vout          int     `json:"vout"`          // 輸出索引 / Output index
+	ScriptPubKey  string  `json:"scriptPubKey"`  // 腳本公鑰（十六進制）/ Script public key (hex)
+	Desc          string  `json:"desc"`          // 匹配的描述符 / Matching descriptor
+	Amount        float64 `json:"amount"`        // 金額（BTC）/ Amount (BTC)
+	Coinbase      bool    `json:"coinbase"`      // 是否為 coinbase 輸出 / Whether this is a coinbase output
+	Height        int     `json:"height"`        // 輸出所在區塊高度 / Height of the block containing the output
+	BlockHash     string  `json:"blockhash"`     // 輸出所在區塊哈希 / Hash of the block containing the output
+	Confirmations int     `json:"confirmations"` // 確認數 / Number of confirmations
+}
+
+// GetBlockFilterResponse 代表 getblockfilter 的回應數據 / represents the response from getblockfilter
+type GetBlockFilterResponse struct {
+	Filter string `json:"filter"` // 序列化的過濾器（十六進制）/ Serialized filter (hex)
+	Header string `json:"header"` // 過濾器頭 / Filter header
+}
+
+// IndexInfo 代表 getindexinfo 返回的索引狀態 / represents the state of an index returned by getindexinfo
+type IndexInfo struct {
+	Synced          bool `json:"synced"`            // 索引是否已同步 / Whether the index is synced
+	BestBlockHeight int  `json:"best_block_height"` // 索引已處理的區塊高度 / Block height the index has processed
+}
+
+// GetBlockVerboseResponse 代表 getblock 在 verbosity 2 或 3 時的回應數據 / represents the response from getblock with verbosity 2 or 3
+type GetBlockVerboseResponse struct {
+	GetBlockResponse
+	Tx []BlockTransaction `json:"tx"` // 區塊中已解碼的交易 / Decoded transactions in block
+}
+
+// BlockTransaction 代表區塊中已解碼的交易 / represents a decoded transaction within a block
+type BlockTransaction struct {
+	GetRawTransactionResponse
+	Fee float64 `json:"fee,omitempty"` // 交易手續費（BTC，需要撤銷數據）/ Transaction fee (BTC, requires undo data)
+}
+
 // GetWalletInfoResponse 代表 getwalletinfo 的回應數據 / represents the response from getwalletinfo
 type GetWalletInfoResponse struct {
 	WalletName            string      `json:"walletname"`                        // 錢包名稱 / Wallet name
@@ -234,6 +480,30 @@ type AddressInfo struct {
 // ListAddressGroupingsResponse 代表 listaddressgroupings 的回應，返回地址分組列表 / represents the response from listaddressgroupings
 type ListAddressGroupingsResponse []AddressGrouping
 
+// ReceivedByAddress 代表 listreceivedbyaddress 返回的地址收款信息 / represents an address entry from listreceivedbyaddress
+type ReceivedByAddress struct {
+	InvolvesWatchonly bool     `json:"involvesWatchonly,omitempty"` // 是否涉及觀察地址 / Whether watch-only addresses are involved
+	Address           string   `json:"address"`                     // 收款地址 / Receiving address
+	Amount            float64  `json:"amount"`                      // 該地址收到的總金額（BTC）/ Total amount received by the address (BTC)
+	Confirmations     int      `json:"confirmations"`               // 最近一筆交易的確認次數 / Confirmations of the most recent transaction included
+	Label             string   `json:"label"`                       // 地址標籤 / Address label
+	TxIDs             []string `json:"txids"`                       // 付款到此地址的交易 ID 列表 / IDs of transactions paying to the address
+}
+
+// ListReceivedByAddressResponse 代表 listreceivedbyaddress 的回應 / represents the response from listreceivedbyaddress
+type ListReceivedByAddressResponse []ReceivedByAddress
+
+// ReceivedByLabel 代表 listreceivedbylabel 返回的標籤收款信息 / represents a label entry from listreceivedbylabel
+type ReceivedByLabel struct {
+	InvolvesWatchonly bool    `json:"involvesWatchonly,omitempty"` // 是否涉及觀察地址 / Whether watch-only addresses are involved
+	Amount            float64 `json:"amount"`                      // 該標籤收到的總金額（BTC）/ Total amount received by addresses with this label (BTC)
+	Confirmations     int     `json:"confirmations"`               // 最近一筆交易的確認次數 / Confirmations of the most recent transaction included
+	Label             string  `json:"label"`                       // 地址標籤 / Address label
+}
+
+// ListReceivedByLabelResponse 代表 listreceivedbylabel 的回應 / represents the response from listreceivedbylabel
+type ListReceivedByLabelResponse []ReceivedByLabel
+
 // GetBlockHashResponse 代表 getblockhash 的回應，返回區塊哈希 / represents the response from getblockhash
 type GetBlockHashResponse string
 
@@ -263,6 +533,15 @@ type RawTransactionVin struct {
 	TxInWitness []string                `json:"txinwitness,omitempty"` // 隔離見證數據 / Witness data
 	Sequence    int64                   `json:"sequence,omitempty"`    // 序列號 / Sequence number
 	Coinbase    string                  `json:"coinbase,omitempty"`    // 基礎幣交易數據（僅對挖礦交易）/ Coinbase data (mining transactions only)
+	Prevout     *RawTransactionPrevout  `json:"prevout,omitempty"`     // 被花費的輸出（getblock verbosity 3）/ Output being spent (getblock verbosity 3)
+}
+
+// RawTransactionPrevout 代表輸入所花費的前序輸出 / represents the previous output spent by an input
+type RawTransactionPrevout struct {
+	Generated    bool                       `json:"generated"`    // 是否為基礎幣輸出 / Whether the output is from a coinbase
+	Height       int                        `json:"height"`       // 前序輸出所在區塊高度 / Height of the block containing the output
+	Value        float64                    `json:"value"`        // 輸出金額（BTC）/ Output amount (BTC)
+	ScriptPubKey RawTransactionScriptPubKey `json:"scriptPubKey"` // 腳本公鑰 / Script public key
 }
 
 // RawTransactionScriptSig 代表腳本簽名 / represents script signature
@@ -282,6 +561,7 @@ type RawTransactionVout struct {
 type RawTransactionScriptPubKey struct {
 	Asm       string   `json:"asm"`                 // 腳本的彙編表示 / Assembly representation of script
 	Hex       string   `json:"hex"`                 // 腳本的十六進制表示 / Hex representation of script
+	Desc      string   `json:"desc,omitempty"`      // 推斷的輸出描述符 / Inferred output descriptor
 	ReqSigs   int      `json:"reqSigs,omitempty"`   // 所需簽名數量 / Required number of signatures
 	Type      string   `json:"type"`                // 腳本類型（pubkey、pubkeyhash、scripthash 等）/ Script type (pubkey, pubkeyhash, scripthash, etc.)
 	Addresses []string `json:"addresses,omitempty"` // 相關地址列表 / List of associated addresses
@@ -310,12 +590,43 @@ type CreateRawTransactionInput struct {
 }
 
 // CreateRawTransactionOutput 代表創建原始交易的輸出 / represents an output for creating raw transaction
+// 設置 Address 和 Amount 為支付輸出，或僅設置 Data 為 OP_RETURN 輸出 / Set Address and Amount for a payment, or only Data for an OP_RETURN output
 type CreateRawTransactionOutput struct {
 	Address string  `json:"address,omitempty"` // 目標地址 / Target address
 	Amount  float64 `json:"amount,omitempty"`  // 輸出金額（BTC）/ Output amount (BTC)
 	Data    string  `json:"data,omitempty"`    // 任意數據（十六進制）/ Arbitrary data (hex)
 }
 
+// AddressOutput 創建支付到地址的輸出 / creates an output paying amount BTC to address
+func AddressOutput(address string, amount float64) CreateRawTransactionOutput {
+	return CreateRawTransactionOutput{Address: address, Amount: amount}
+}
+
+// DataOutput 創建攜帶數據的 OP_RETURN 輸出 / creates an OP_RETURN output carrying data
+func DataOutput(data []byte) CreateRawTransactionOutput {
+	return CreateRawTransactionOutput{Data: hex.EncodeToString(data)}
+}
+
+// MarshalJSON 將輸出編碼為 {"地址": 金額} 或 {"data": "十六進制"} / encodes the output as {"address": amount} or {"data": "hex"}
+func (o CreateRawTransactionOutput) MarshalJSON() ([]byte, error) {
+	switch {
+	case o.Address != "" && o.Data != "":
+		return nil, fmt.Errorf("output cannot have both an address and data")
+	case o.Address != "":
+		// Fixed 8 decimals avoids float artefacts such as 0.30000000000000004
+		amount := json.RawMessage(strconv.FormatFloat(o.Amount, 'f', 8, 64))
+		return json.Marshal(map[string]json.RawMessage{o.Address: amount})
+	case o.Data != "":
+		return json.Marshal(map[string]string{"data": o.Data})
+	default:
+		return nil, fmt.Errorf("output needs an address or data")
+	}
+}
+
+// CreateRawTransactionOutputs 代表按順序排列的交易輸出 / represents transaction outputs in order
+// 以數組形式編碼，保持輸出順序 / Encoded as an array of objects so the output order is kept
+type CreateRawTransactionOutputs []CreateRawTransactionOutput
+
 // SignRawTransactionResponse 代表 signrawtransactionwithwallet 的回應數據 / represents the response from signrawtransactionwithwallet
 type SignRawTransactionResponse struct {
 	Hex      string                    `json:"hex"`              // 簽名後的交易十六進制 / Signed transaction hex
@@ -332,6 +643,78 @@ type SignRawTransactionError struct {
 	Error     string `json:"error"`     // 錯誤描述 / Error description
 }
 
+// RawTransactionPrevTx 代表簽名時使用的前序輸出 / represents a previous output used when signing
+type RawTransactionPrevTx struct {
+	TxID          string  `json:"txid"`                    // 前序交易 ID / Previous transaction ID
+	Vout          int     `json:"vout"`                    // 前序輸出索引 / Previous output index
+	ScriptPubKey  string  `json:"scriptPubKey"`            // 輸出腳本（十六進制）/ Output script (hex)
+	RedeemScript  string  `json:"redeemScript,omitempty"`  // P2SH 贖回腳本（十六進制）/ P2SH redeem script (hex)
+	WitnessScript string  `json:"witnessScript,omitempty"` // P2WSH 見證腳本（十六進制）/ P2WSH witness script (hex)
+	Amount        float64 `json:"amount,omitempty"`        // 輸出金額（BTC，隔離見證輸入必需）/ Output amount (BTC, required for segwit inputs)
+}
+
+// FundRawTransactionResponse 代表 fundrawtransaction 的回應數據 / represents the response from fundrawtransaction
+type FundRawTransactionResponse struct {
+	Hex       string  `json:"hex"`       // 已注資的交易（十六進制）/ Funded transaction (hex)
+	Fee       float64 `json:"fee"`       // 手續費（BTC）/ Fee (BTC)
+	ChangePos int     `json:"changepos"` // 找零輸出的位置，-1 表示無找零 / Position of the change output, -1 if none
+}
+
+// DecodeScriptResponse 代表 decodescript 的回應數據 / represents the response from decodescript
+type DecodeScriptResponse struct {
+	Asm     string              `json:"asm"`               // 腳本的彙編表示 / Assembly representation of script
+	Desc    string              `json:"desc"`              // 推斷的輸出描述符 / Inferred output descriptor
+	Type    string              `json:"type"`              // 腳本類型 / Script type
+	Address string              `json:"address,omitempty"` // 腳本對應的地址（如有）/ Address of the script, if any
+	P2SH    string              `json:"p2sh,omitempty"`    // 腳本的 P2SH 地址 / P2SH address wrapping the script
+	Segwit  *DecodeScriptSegwit `json:"segwit,omitempty"`  // 腳本的隔離見證包裝 / Segwit wrapping of the script
+}
+
+// DecodeScriptSegwit 代表 decodescript 中腳本的隔離見證形式 / represents the segwit form of a script in decodescript
+type DecodeScriptSegwit struct {
+	Asm        string `json:"asm"`                   // 見證輸出腳本的彙編表示 / Assembly of the witness output script
+	Hex        string `json:"hex"`                   // 見證輸出腳本（十六進制）/ Witness output script (hex)
+	Type       string `json:"type"`                  // 輸出類型 / Output type
+	Address    string `json:"address,omitempty"`     // 隔離見證地址 / Segwit address
+	Desc       string `json:"desc"`                  // 推斷的輸出描述符 / Inferred output descriptor
+	P2SHSegwit string `json:"p2sh-segwit,omitempty"` // 包裝在 P2SH 中的隔離見證地址 / Segwit address wrapped in P2SH
+}
+
+// TestMempoolAcceptResult 代表 testmempoolaccept 中單個交易的結果 / represents the result for a single transaction in testmempoolaccept
+type TestMempoolAcceptResult struct {
+	TxID          string             `json:"txid"`                     // 交易 ID / Transaction ID
+	WTxID         string             `json:"wtxid"`                    // 見證交易 ID / Witness transaction ID
+	PackageError  string             `json:"package-error,omitempty"`  // 交易包驗證失敗的原因 / Package validation error, if any
+	Allowed       bool               `json:"allowed"`                  // 交易是否會被內存池接受 / Whether the transaction would be accepted to the mempool
+	VSize         int                `json:"vsize,omitempty"`          // 虛擬交易大小（僅在接受時）/ Virtual transaction size (only if allowed)
+	Fees          *MempoolAcceptFees `json:"fees,omitempty"`           // 手續費信息（僅在接受時）/ Fee information (only if allowed)
+	RejectReason  string             `json:"reject-reason,omitempty"`  // 拒絕原因 / Rejection reason
+	RejectDetails string             `json:"reject-details,omitempty"` // 拒絕的詳細信息 / Rejection details
+}
+
+// MempoolAcceptFees 代表被接受交易的手續費信息 / represents the fee information of an accepted transaction
+type MempoolAcceptFees struct {
+	Base              float64  `json:"base"`                         // 交易手續費（BTC）/ Transaction fee (BTC)
+	EffectiveFeeRate  float64  `json:"effective-feerate,omitempty"`  // 有效手續費率（BTC/kvB）/ Effective feerate (BTC/kvB)
+	EffectiveIncludes []string `json:"effective-includes,omitempty"` // 計入有效手續費率的交易 wtxid / Wtxids of transactions included in the effective feerate
+}
+
+// SubmitPackageResponse 代表 submitpackage 的回應數據 / represents the response from submitpackage
+type SubmitPackageResponse struct {
+	PackageMsg           string                           `json:"package_msg"`                     // 交易包處理結果 / Package processing result ("success" when all were accepted)
+	TxResults            map[string]SubmitPackageTxResult `json:"tx-results"`                      // 以 wtxid 為鍵的交易結果 / Per-transaction results keyed by wtxid
+	ReplacedTransactions []string                         `json:"replaced-transactions,omitempty"` // 被替換的交易 ID / Txids of replaced transactions
+}
+
+// SubmitPackageTxResult 代表 submitpackage 中單個交易的結果 / represents the result for a single transaction in submitpackage
+type SubmitPackageTxResult struct {
+	TxID       string             `json:"txid"`                  // 交易 ID / Transaction ID
+	OtherWTxID string             `json:"other-wtxid,omitempty"` // 內存池中同 txid 不同見證的交易 / Wtxid of a same-txid different-witness transaction in the mempool
+	VSize      int                `json:"vsize,omitempty"`       // 虛擬交易大小 / Virtual transaction size
+	Fees       *MempoolAcceptFees `json:"fees,omitempty"`        // 手續費信息 / Fee information
+	Error      string             `json:"error,omitempty"`       // 交易被拒絕的原因 / Reason the transaction was rejected
+}
+
 // DumpPrivKeyResponse 代表 dumpprivkey 的回應數據 / represents the response from dumpprivkey
 type DumpPrivKeyResponse struct {
 	PrivateKey string `json:"privatekey"` // 導出的私鑰（WIF 格式）/ Exported private key (WIF format)
@@ -342,6 +725,76 @@ type ImportPrivKeyResponse struct {
 	Success bool `json:"success"` // 導入是否成功 / Whether import was successful
 }
 
+// DescriptorRange 代表範圍描述符的索引範圍 [起始, 結束] / represents the [begin, end] index range of a ranged descriptor
+type DescriptorRange [2]int
+
+// DescriptorTimestamp 代表描述符的創建時間（UNIX 時間戳或 "now"），零值為 "now" / represents a descriptor creation time (UNIX timestamp or "now"), the zero value is "now"
+type DescriptorTimestamp int64
+
+const (
+	// TimestampNow 表示跳過重新掃描的 "now" 時間戳 / is the "now" timestamp, which skips rescanning
+	TimestampNow DescriptorTimestamp = 0
+	// TimestampGenesis 從創世區塊開始完整重新掃描 / rescans the whole chain from the genesis block
+	TimestampGenesis DescriptorTimestamp = -1
+)
+
+// MarshalJSON 將 TimestampNow 編碼為 "now"，TimestampGenesis 編碼為 0 / encodes TimestampNow as "now" and TimestampGenesis as 0
+func (t DescriptorTimestamp) MarshalJSON() ([]byte, error) {
+	switch {
+	case t == TimestampNow:
+		return []byte(`"now"`), nil
+	case t == TimestampGenesis:
+		return []byte(`0`), nil
+	case t < 0:
+		return nil, fmt.Errorf("invalid descriptor timestamp %d", int64(t))
+	}
+	return json.Marshal(int64(t))
+}
+
+// ImportDescriptorRequest 代表 importdescriptors 的單個導入請求 / represents a single import request for importdescriptors
+type ImportDescriptorRequest struct {
+	Desc      string              `json:"desc"`                 // 帶校驗和的描述符 / Descriptor including checksum
+	Active    bool                `json:"active,omitempty"`     // 是否作為活躍描述符用於生成地址 / Whether to use it to generate new addresses
+	Range     *DescriptorRange    `json:"range,omitempty"`      // 範圍描述符的導入範圍 / Range to import for ranged descriptors
+	NextIndex int                 `json:"next_index,omitempty"` // 下一個生成地址的索引 / Next index to generate addresses from
+	Timestamp DescriptorTimestamp `json:"timestamp"`            // 重新掃描的起始時間 / Time from which to rescan
+	Internal  bool                `json:"internal,omitempty"`   // 是否為找零（內部）描述符 / Whether it is an internal (change) descriptor
+	Label     string              `json:"label,omitempty"`      // 地址標籤（僅非內部描述符）/ Address label (non-internal descriptors only)
+}
+
+// ImportDescriptorResult 代表 importdescriptors 的單個導入結果 / represents the result of a single importdescriptors request
+type ImportDescriptorResult struct {
+	Success  bool      `json:"success"`            // 導入是否成功 / Whether the import succeeded
+	Warnings []string  `json:"warnings,omitempty"` // 警告信息 / Warning messages
+	Error    *RPCError `json:"error,omitempty"`    // 導入失敗的錯誤 / Error if the import failed
+}
+
+// ListDescriptorsResponse 代表 listdescriptors 的回應數據 / represents the response from listdescriptors
+type ListDescriptorsResponse struct {
+	WalletName  string             `json:"wallet_name"` // 錢包名稱 / Wallet name
+	Descriptors []WalletDescriptor `json:"descriptors"` // 錢包中的描述符列表 / Descriptors in the wallet
+}
+
+// WalletDescriptor 代表 listdescriptors 返回的描述符 / represents a descriptor returned by listdescriptors
+type WalletDescriptor struct {
+	Desc      string           `json:"desc"`                 // 帶校驗和的描述符 / Descriptor including checksum
+	Timestamp int64            `json:"timestamp"`            // 描述符的創建時間 / Descriptor creation time
+	Active    bool             `json:"active"`               // 是否為活躍描述符 / Whether the descriptor is active
+	Internal  bool             `json:"internal,omitempty"`   // 是否為找零描述符（僅活躍描述符）/ Whether it is a change descriptor (active descriptors only)
+	Range     *DescriptorRange `json:"range,omitempty"`      // 已擴展的範圍（僅範圍描述符）/ Expanded range (ranged descriptors only)
+	Next      int              `json:"next,omitempty"`       // 下一個生成地址的索引（已棄用）/ Next index to generate addresses from (deprecated)
+	NextIndex int              `json:"next_index,omitempty"` // 下一個生成地址的索引 / Next index to generate addresses from
+}
+
+// GetDescriptorInfoResponse 代表 getdescriptorinfo 的回應數據 / represents the response from getdescriptorinfo
+type GetDescriptorInfoResponse struct {
+	Descriptor     string `json:"descriptor"`     // 規範化的描述符（不含私鑰）/ Canonical descriptor (without private keys)
+	Checksum       string `json:"checksum"`       // 輸入描述符的校驗和 / Checksum of the input descriptor
+	IsRange        bool   `json:"isrange"`        // 是否為範圍描述符 / Whether the descriptor is ranged
+	IsSolvable     bool   `json:"issolvable"`     // 是否可解 / Whether the descriptor is solvable
+	HasPrivateKeys bool   `json:"hasprivatekeys"` // 是否包含私鑰 / Whether the input descriptor contains private keys
+}
+
 // CreateMultisigResponse 代表 createmultisig 的回應數據 / represents the response from createmultisig
 type CreateMultisigResponse struct {
 	Address      string `json:"address"`      // 多重簽名地址 / Multisig address
@@ -360,24 +813,230 @@ type GetRawMempoolResponse []string
 // GetRawMempoolVerboseResponse 代表 getrawmempool 的詳細回應數據 / represents the verbose response from getrawmempool
 type GetRawMempoolVerboseResponse map[string]GetRawMempoolEntry
 
-// GetRawMempoolEntry 代表詳細內存池回應中的單個條目 / represents a single entry in verbose mempool response
+// GetRawMempoolSequenceResponse 代表帶 mempool_sequence 的 getrawmempool 回應 / represents the response from getrawmempool with mempool_sequence
+type GetRawMempoolSequenceResponse struct {
+	TxIDs           []string `json:"txids"`            // 內存池中的交易 ID 列表 / Transaction IDs in the mempool
+	MempoolSequence uint64   `json:"mempool_sequence"` // 快照對應的內存池序號 / Mempool sequence the snapshot corresponds to
+}
+
+// GetRawMempoolEntry 代表詳細內存池回應中的單個條目，也是 getmempoolentry 的回應 / represents a single entry in verbose mempool responses, also returned by getmempoolentry
 type GetRawMempoolEntry struct {
-	Vsize             int      `json:"vsize"`              // 虛擬交易大小 / Virtual transaction size
-	Weight            int      `json:"weight"`             // 交易權重 / Transaction weight
-	Fee               float64  `json:"fee"`                // 交易手續費（BTC）/ Transaction fee (BTC)
-	ModifiedFee       float64  `json:"modifiedfee"`        // 修改後的手續費（BTC）/ Modified fee (BTC)
-	Time              int64    `json:"time"`               // 交易進入內存池的時間 / Time when transaction entered mempool
-	Height            int      `json:"height"`             // 交易進入內存池時的區塊高度 / Block height when transaction entered mempool
-	DescendantCount   int      `json:"descendantcount"`    // 後代交易數量 / Number of descendant transactions
-	DescendantSize    int      `json:"descendantsize"`     // 後代交易總大小 / Total size of descendant transactions
-	DescendantFees    float64  `json:"descendantfees"`     // 後代交易總手續費（BTC）/ Total fees of descendant transactions (BTC)
-	AncestorCount     int      `json:"ancestorcount"`      // 祖先交易數量 / Number of ancestor transactions
-	AncestorSize      int      `json:"ancestorsize"`       // 祖先交易總大小 / Total size of ancestor transactions
-	AncestorFees      float64  `json:"ancestorfees"`       // 祖先交易總手續費（BTC）/ Total fees of ancestor transactions (BTC)
-	WTxID             string   `json:"wtxid"`              // 見證交易 ID / Witness transaction ID
-	FeeRate           float64  `json:"feerate"`            // 手續費率（BTC/kB）/ Fee rate (BTC/kB)
-	Depends           []string `json:"depends"`            // 依賴的交易 ID 列表 / List of dependent transaction IDs
-	SpentBy           []string `json:"spentby"`            // 花費此交易輸出的交易 ID 列表 / List of transaction IDs spending this transaction's outputs
-	BIP125Replaceable bool     `json:"bip125-replaceable"` // 是否支持 BIP125 替換 / Whether BIP125 replacement is enabled
-	Unbroadcast       bool     `json:"unbroadcast"`        // 是否為未廣播交易 / Whether transaction is unbroadcast
+	Vsize             int              `json:"vsize"`              // 虛擬交易大小 / Virtual transaction size
+	Weight            int              `json:"weight"`             // 交易權重 / Transaction weight
+	Time              int64            `json:"time"`               // 交易進入內存池的時間 / Time when transaction entered mempool
+	Height            int              `json:"height"`             // 交易進入內存池時的區塊高度 / Block height when transaction entered mempool
+	DescendantCount   int              `json:"descendantcount"`    // 後代交易數量 / Number of descendant transactions
+	DescendantSize    int              `json:"descendantsize"`     // 後代交易總大小 / Total size of descendant transactions
+	AncestorCount     int              `json:"ancestorcount"`      // 祖先交易數量 / Number of ancestor transactions
+	AncestorSize      int              `json:"ancestorsize"`       // 祖先交易總大小 / Total size of ancestor transactions
+	WTxID             string           `json:"wtxid"`              // 見證交易 ID / Witness transaction ID
+	Fees              MempoolEntryFees `json:"fees"`               // 手續費明細 / Fee breakdown
+	Depends           []string         `json:"depends"`            // 依賴的交易 ID 列表 / List of dependent transaction IDs
+	SpentBy           []string         `json:"spentby"`            // 花費此交易輸出的交易 ID 列表 / List of transaction IDs spending this transaction's outputs
+	BIP125Replaceable bool             `json:"bip125-replaceable"` // 是否支持 BIP125 替換 / Whether BIP125 replacement is enabled
+	Unbroadcast       bool             `json:"unbroadcast"`        // 是否為未廣播交易 / Whether transaction is unbroadcast
+}
+
+// BaseFeeRateSatVB returns the transaction's own fee rate in sat/vB, based on its base fee
+// The unit is spelled out so it is not mistaken for the removed BTC/kB FeeRate field.
+func (e *GetRawMempoolEntry) BaseFeeRateSatVB() float64 {
+	if e.Vsize == 0 {
+		return 0
+	}
+	return e.Fees.Base * 1e8 / float64(e.Vsize)
+}
+
+// MempoolEntryFees 代表內存池條目的手續費明細 / represents the fee breakdown of a mempool entry
+type MempoolEntryFees struct {
+	Base       float64 `json:"base"`       // 交易手續費（BTC）/ Transaction fee (BTC)
+	Modified   float64 `json:"modified"`   // 經 prioritisetransaction 調整後的手續費（BTC）/ Fee adjusted by prioritisetransaction (BTC)
+	Ancestor   float64 `json:"ancestor"`   // 內存池中祖先交易（含自身）的修改後手續費（BTC）/ Modified fees of in-mempool ancestors, including this one (BTC)
+	Descendant float64 `json:"descendant"` // 內存池中後代交易（含自身）的修改後手續費（BTC）/ Modified fees of in-mempool descendants, including this one (BTC)
+}
+
+// === PSBT Types ===
+
+// FundOptions 代表 walletcreatefundedpsbt 和 fundrawtransaction 的資金選項 / represents funding options for walletcreatefundedpsbt and fundrawtransaction
+type FundOptions struct {
+	AddInputs              *bool    `json:"add_inputs,omitempty"`             // 是否自動添加錢包輸入 / Whether to automatically add wallet inputs
+	IncludeUnsafe          bool     `json:"include_unsafe,omitempty"`         // 是否使用未確認的不安全輸入 / Whether to use unconfirmed unsafe inputs
+	ChangeAddress          string   `json:"changeAddress,omitempty"`          // 找零地址 / Address to receive the change
+	ChangePosition         *int     `json:"changePosition,omitempty"`         // 找零輸出的索引 / Index of the change output
+	ChangeType             string   `json:"change_type,omitempty"`            // 找零地址類型 / Change address type
+	IncludeWatching        bool     `json:"includeWatching,omitempty"`        // 是否使用觀察地址的輸入 / Whether to use inputs from watch-only addresses
+	LockUnspents           bool     `json:"lockUnspents,omitempty"`           // 是否鎖定選中的 UTXO / Whether to lock the selected UTXOs
+	FeeRate                float64  `json:"fee_rate,omitempty"`               // 手續費率（sat/vB）/ Fee rate (sat/vB)
+	FeeRateBTCPerKvB       float64  `json:"feeRate,omitempty"`                // 手續費率（BTC/kvB）/ Fee rate (BTC/kvB)
+	SubtractFeeFromOutputs []int    `json:"subtractFeeFromOutputs,omitempty"` // 從中扣除手續費的輸出索引 / Output indexes to subtract the fee from
+	Replaceable            *bool    `json:"replaceable,omitempty"`            // 是否標記為 BIP125 可替換 / Whether to signal BIP125 replaceability
+	ConfTarget             int      `json:"conf_target,omitempty"`            // 確認目標區塊數 / Confirmation target in blocks
+	EstimateMode           string   `json:"estimate_mode,omitempty"`          // 手續費估算模式 / Fee estimation mode
+	SolvingData            *Solving `json:"solving_data,omitempty"`           // 用於估算外部輸入大小的數據 / Data used to estimate the size of external inputs
+}
+
+// Solving 代表用於解析外部輸入的公鑰、腳本和描述符 / represents pubkeys, scripts and descriptors used to solve external inputs
+type Solving struct {
+	PubKeys     []string `json:"pubkeys,omitempty"`     // 公鑰列表（十六進制）/ Public keys (hex)
+	Scripts     []string `json:"scripts,omitempty"`     // 腳本列表（十六進制）/ Scripts (hex)
+	Descriptors []string `json:"descriptors,omitempty"` // 描述符列表 / Descriptors
+}
+
+// WalletCreateFundedPSBTResponse 代表 walletcreatefundedpsbt 的回應數據 / represents the response from walletcreatefundedpsbt
+type WalletCreateFundedPSBTResponse struct {
+	PSBT      string  `json:"psbt"`      // 部分簽名交易（base64）/ Partially signed transaction (base64)
+	Fee       float64 `json:"fee"`       // 手續費（BTC）/ Fee (BTC)
+	ChangePos int     `json:"changepos"` // 找零輸出的位置，-1 表示無找零 / Position of the change output, -1 if none
+}
+
+// WalletProcessPSBTResponse 代表 walletprocesspsbt 的回應數據 / represents the response from walletprocesspsbt
+type WalletProcessPSBTResponse struct {
+	PSBT     string `json:"psbt"`          // 處理後的部分簽名交易（base64）/ Processed partially signed transaction (base64)
+	Complete bool   `json:"complete"`      // 交易是否已有完整簽名 / Whether the transaction has a complete set of signatures
+	Hex      string `json:"hex,omitempty"` // 完成時提取的網絡交易（十六進制）/ Extracted network transaction when complete (hex)
+}
+
+// FinalizePSBTResponse 代表 finalizepsbt 的回應數據 / represents the response from finalizepsbt
+type FinalizePSBTResponse struct {
+	PSBT     string `json:"psbt,omitempty"` // 未提取時的部分簽名交易（base64）/ Partially signed transaction if not extracted (base64)
+	Hex      string `json:"hex,omitempty"`  // 已提取的網絡交易（十六進制）/ Extracted network transaction (hex)
+	Complete bool   `json:"complete"`       // 交易是否已有完整簽名 / Whether the transaction has a complete set of signatures
+}
+
+// DecodePSBTResponse 代表 decodepsbt 的回應數據 / represents the response from decodepsbt
+type DecodePSBTResponse struct {
+	Tx          GetRawTransactionResponse `json:"tx"`                    // 解碼後的未簽名交易 / Decoded unsigned transaction
+	GlobalXpubs []PSBTGlobalXpub          `json:"global_xpubs"`          // 全局擴展公鑰 / Global extended public keys
+	PSBTVersion int                       `json:"psbt_version"`          // PSBT 版本號 / PSBT version number
+	Proprietary []PSBTProprietary         `json:"proprietary,omitempty"` // 全局專有字段 / Global proprietary fields
+	Unknown     map[string]string         `json:"unknown,omitempty"`     // 未知的全局字段 / Unknown global fields
+	Inputs      []PSBTInput               `json:"inputs"`                // 輸入列表 / Inputs
+	Outputs     []PSBTOutput              `json:"outputs"`               // 輸出列表 / Outputs
+	Fee         float64                   `json:"fee,omitempty"`         // 交易手續費（所有輸入 UTXO 已知時）/ Transaction fee (if all input UTXOs are known)
+}
+
+// PSBTGlobalXpub 代表 PSBT 全局擴展公鑰 / represents a PSBT global extended public key
+type PSBTGlobalXpub struct {
+	Xpub              string `json:"xpub"`               // 擴展公鑰 / Extended public key
+	MasterFingerprint string `json:"master_fingerprint"` // 主密鑰指紋 / Master key fingerprint
+	Path              string `json:"path"`               // 派生路徑 / Derivation path
+}
+
+// PSBTProprietary 代表 PSBT 專有字段 / represents a PSBT proprietary field
+type PSBTProprietary struct {
+	Identifier string `json:"identifier"` // 專有標識符（十六進制）/ Proprietary identifier (hex)
+	Subtype    int    `json:"subtype"`    // 專有子類型 / Proprietary subtype
+	Key        string `json:"key"`        // 完整鍵（十六進制）/ Full key (hex)
+	Value      string `json:"value"`      // 值（十六進制）/ Value (hex)
+}
+
+// PSBTScript 代表 PSBT 中的腳本 / represents a script in a PSBT
+type PSBTScript struct {
+	Asm  string `json:"asm"`            // 腳本的彙編表示 / Assembly representation of script
+	Hex  string `json:"hex"`            // 腳本的十六進制表示 / Hex representation of script
+	Type string `json:"type,omitempty"` // 腳本類型 / Script type
+}
+
+// PSBTWitnessUTXO 代表 PSBT 輸入的見證 UTXO / represents the witness UTXO of a PSBT input
+type PSBTWitnessUTXO struct {
+	Amount       float64                    `json:"amount"`       // 金額（BTC）/ Amount (BTC)
+	ScriptPubKey RawTransactionScriptPubKey `json:"scriptPubKey"` // 腳本公鑰 / Script public key
+}
+
+// PSBTBip32Deriv 代表 BIP32 派生信息 / represents BIP32 derivation information
+type PSBTBip32Deriv struct {
+	PubKey            string `json:"pubkey"`             // 公鑰（十六進制）/ Public key (hex)
+	MasterFingerprint string `json:"master_fingerprint"` // 主密鑰指紋 / Master key fingerprint
+	Path              string `json:"path"`               // 派生路徑 / Derivation path
+}
+
+// PSBTTaprootBip32Deriv 代表 Taproot BIP32 派生信息 / represents taproot BIP32 derivation information
+type PSBTTaprootBip32Deriv struct {
+	PubKey            string   `json:"pubkey"`             // x-only 公鑰（十六進制）/ X-only public key (hex)
+	MasterFingerprint string   `json:"master_fingerprint"` // 主密鑰指紋 / Master key fingerprint
+	Path              string   `json:"path"`               // 派生路徑 / Derivation path
+	LeafHashes        []string `json:"leaf_hashes"`        // 使用此公鑰的葉子哈希 / Hashes of the leaves this key is used in
+}
+
+// PSBTTaprootScriptPathSig 代表 Taproot 腳本路徑簽名 / represents a taproot script path signature
+type PSBTTaprootScriptPathSig struct {
+	PubKey   string `json:"pubkey"`    // x-only 公鑰（十六進制）/ X-only public key (hex)
+	LeafHash string `json:"leaf_hash"` // 葉子哈希 / Leaf hash
+	Sig      string `json:"sig"`       // 簽名（十六進制）/ Signature (hex)
+}
+
+// PSBTTaprootScript 代表 Taproot 葉子腳本及其控制塊 / represents a taproot leaf script and its control blocks
+type PSBTTaprootScript struct {
+	Script        string   `json:"script"`         // 葉子腳本（十六進制）/ Leaf script (hex)
+	LeafVer       int      `json:"leaf_ver"`       // 葉子版本 / Leaf version
+	ControlBlocks []string `json:"control_blocks"` // 控制塊列表（十六進制）/ Control blocks (hex)
+}
+
+// PSBTTaprootTreeLeaf 代表輸出 Taproot 樹中的葉子 / represents a leaf of an output taproot tree
+type PSBTTaprootTreeLeaf struct {
+	Depth   int    `json:"depth"`    // 葉子深度 / Leaf depth
+	LeafVer int    `json:"leaf_ver"` // 葉子版本 / Leaf version
+	Script  string `json:"script"`   // 葉子腳本（十六進制）/ Leaf script (hex)
+}
+
+// PSBTInput 代表 decodepsbt 中的輸入 / represents an input in decodepsbt
+type PSBTInput struct {
+	NonWitnessUTXO        *GetRawTransactionResponse `json:"non_witness_utxo,omitempty"`         // 完整的前序交易 / Full previous transaction
+	WitnessUTXO           *PSBTWitnessUTXO           `json:"witness_utxo,omitempty"`             // 見證 UTXO / Witness UTXO
+	PartialSignatures     map[string]string          `json:"partial_signatures,omitempty"`       // 公鑰到部分簽名的映射 / Map of pubkey to partial signature
+	Sighash               string                     `json:"sighash,omitempty"`                  // 簽名哈希類型 / Sighash type
+	RedeemScript          *PSBTScript                `json:"redeem_script,omitempty"`            // 贖回腳本 / Redeem script
+	WitnessScript         *PSBTScript                `json:"witness_script,omitempty"`           // 見證腳本 / Witness script
+	Bip32Derivs           []PSBTBip32Deriv           `json:"bip32_derivs,omitempty"`             // BIP32 派生信息 / BIP32 derivations
+	FinalScriptSig        *RawTransactionScriptSig   `json:"final_scriptSig,omitempty"`          // 最終腳本簽名 / Final scriptSig
+	FinalScriptWitness    []string                   `json:"final_scriptwitness,omitempty"`      // 最終見證數據 / Final script witness
+	Ripemd160Preimages    map[string]string          `json:"ripemd160_preimages,omitempty"`      // RIPEMD160 原像 / RIPEMD160 preimages
+	Sha256Preimages       map[string]string          `json:"sha256_preimages,omitempty"`         // SHA256 原像 / SHA256 preimages
+	Hash160Preimages      map[string]string          `json:"hash160_preimages,omitempty"`        // HASH160 原像 / HASH160 preimages
+	Hash256Preimages      map[string]string          `json:"hash256_preimages,omitempty"`        // HASH256 原像 / HASH256 preimages
+	TaprootKeyPathSig     string                     `json:"taproot_key_path_sig,omitempty"`     // Taproot 密鑰路徑簽名 / Taproot key path signature
+	TaprootScriptPathSigs []PSBTTaprootScriptPathSig `json:"taproot_script_path_sigs,omitempty"` // Taproot 腳本路徑簽名 / Taproot script path signatures
+	TaprootScripts        []PSBTTaprootScript        `json:"taproot_scripts,omitempty"`          // Taproot 葉子腳本 / Taproot leaf scripts
+	TaprootBip32Derivs    []PSBTTaprootBip32Deriv    `json:"taproot_bip32_derivs,omitempty"`     // Taproot BIP32 派生信息 / Taproot BIP32 derivations
+	TaprootInternalKey    string                     `json:"taproot_internal_key,omitempty"`     // Taproot 內部公鑰 / Taproot internal key
+	TaprootMerkleRoot     string                     `json:"taproot_merkle_root,omitempty"`      // Taproot 默克爾根 / Taproot merkle root
+	Proprietary           []PSBTProprietary          `json:"proprietary,omitempty"`              // 專有字段 / Proprietary fields
+	Unknown               map[string]string          `json:"unknown,omitempty"`                  // 未知字段 / Unknown fields
+}
+
+// PSBTOutput 代表 decodepsbt 中的輸出 / represents an output in decodepsbt
+type PSBTOutput struct {
+	RedeemScript       *PSBTScript             `json:"redeem_script,omitempty"`        // 贖回腳本 / Redeem script
+	WitnessScript      *PSBTScript             `json:"witness_script,omitempty"`       // 見證腳本 / Witness script
+	Bip32Derivs        []PSBTBip32Deriv        `json:"bip32_derivs,omitempty"`         // BIP32 派生信息 / BIP32 derivations
+	TaprootInternalKey string                  `json:"taproot_internal_key,omitempty"` // Taproot 內部公鑰 / Taproot internal key
+	TaprootTree        []PSBTTaprootTreeLeaf   `json:"taproot_tree,omitempty"`         // Taproot 腳本樹 / Taproot script tree
+	TaprootBip32Derivs []PSBTTaprootBip32Deriv `json:"taproot_bip32_derivs,omitempty"` // Taproot BIP32 派生信息 / Taproot BIP32 derivations
+	Proprietary        []PSBTProprietary       `json:"proprietary,omitempty"`          // 專有字段 / Proprietary fields
+	Unknown            map[string]string       `json:"unknown,omitempty"`              // 未知字段 / Unknown fields
+}
+
+// AnalyzePSBTResponse 代表 analyzepsbt 的回應數據 / represents the response from analyzepsbt
+type AnalyzePSBTResponse struct {
+	Inputs           []AnalyzePSBTInput `json:"inputs,omitempty"`            // 每個輸入的分析 / Analysis of each input
+	EstimatedVSize   int                `json:"estimated_vsize,omitempty"`   // 估算的最終交易虛擬大小 / Estimated vsize of the final transaction
+	EstimatedFeeRate float64            `json:"estimated_feerate,omitempty"` // 估算的最終交易手續費率（BTC/kvB）/ Estimated feerate of the final transaction (BTC/kvB)
+	Fee              float64            `json:"fee,omitempty"`               // 交易手續費（BTC）/ Transaction fee (BTC)
+	Next             string             `json:"next"`                        // 下一個需要處理的角色 / Role of the next person to handle the PSBT
+	Error            string             `json:"error,omitempty"`             // PSBT 無效時的錯誤信息 / Error message if the PSBT is invalid
+}
+
+// AnalyzePSBTInput 代表 analyzepsbt 中單個輸入的分析 / represents the analysis of a single input in analyzepsbt
+type AnalyzePSBTInput struct {
+	HasUTXO bool                `json:"has_utxo"`          // 是否已提供 UTXO / Whether a UTXO is provided
+	IsFinal bool                `json:"is_final"`          // 輸入是否已完成 / Whether the input is finalized
+	Missing *AnalyzePSBTMissing `json:"missing,omitempty"` // 完成輸入所缺少的數據 / Data missing to finalize the input
+	Next    string              `json:"next,omitempty"`    // 此輸入的下一個角色 / Role of the next person to handle this input
+}
+
+// AnalyzePSBTMissing 代表輸入缺少的公鑰、簽名和腳本 / represents pubkeys, signatures and scripts an input is missing
+type AnalyzePSBTMissing struct {
+	PubKeys       []string `json:"pubkeys,omitempty"`       // 缺少 BIP32 派生的公鑰 ID / Key IDs of pubkeys whose BIP32 derivation is missing
+	Signatures    []string `json:"signatures,omitempty"`    // 缺少簽名的公鑰 ID / Key IDs of pubkeys whose signature is missing
+	RedeemScript  string   `json:"redeemscript,omitempty"`  // 缺少的贖回腳本哈希 / Hash160 of the missing redeem script
+	WitnessScript string   `json:"witnessscript,omitempty"` // 缺少的見證腳本哈希 / SHA256 of the missing witness script
 }
@@ -3,6 +3,7 @@ package btcrpc
 import (
 	"encoding/json"
 	"fmt"
+	"iter"
 )
 
 // CreateWallet calls the createwallet RPC method
@@ -248,6 +249,48 @@ func (c *Client) ListTransactions(walletName, label string, count, skip int, inc
 	return transactions, nil
 }
 
+// ListTransactionsIter pages through all wallet transactions with listtransactions
+// Transactions are yielded from the most recent backwards. Entries that shift across a
+// page boundary because new transactions arrived while paging are deduplicated on
+// txid, vout and category. Iteration stops at the first error, which is yielded once.
+// walletName: name of the wallet to list transactions from
+// label: optional label to filter transactions (use "*" for all)
+// pageSize: number of transactions to fetch per call (default 100)
+// includeWatchonly: include watch-only transactions
+func (c *Client) ListTransactionsIter(walletName, label string, pageSize int, includeWatchonly bool) iter.Seq2[Transaction, error] {
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	return func(yield func(Transaction, error) bool) {
+		seen := make(map[string]struct{})
+		for skip := 0; ; skip += pageSize {
+			page, err := c.ListTransactions(walletName, label, pageSize, skip, includeWatchonly)
+			if err != nil {
+				yield(Transaction{}, err)
+				return
+			}
+
+			// listtransactions returns each page oldest first
+			for i := len(page) - 1; i >= 0; i-- {
+				key := walletTxKey(page[i])
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				if !yield(page[i], nil) {
+					return
+				}
+			}
+
+			// A short page means we reached the oldest transaction
+			if len(page) < pageSize {
+				return
+			}
+		}
+	}
+}
+
 // ValidateAddress calls the validateaddress RPC method
 // address: bitcoin address to validate
 func (c *Client) ValidateAddress(address string) (*ValidateAddressResponse, error) {
@@ -474,3 +517,149 @@ func (c *Client) ListAddressGroupings(walletName string) ([]AddressGrouping, err
 
 	return groupings, nil
 }
+
+// ListReceivedByAddress calls the listreceivedbyaddress RPC method
+// walletName: name of the wallet to query
+// minconf: minimum number of confirmations before payments are included (optional, default 1)
+// includeEmpty: include addresses that haven't received any payments
+// includeWatchonly: include watch-only addresses
+// addressFilter: if set, only return information for this address
+// includeImmatureCoinbase: include immature coinbase transactions
+func (c *Client) ListReceivedByAddress(walletName string, minconf *int, includeEmpty, includeWatchonly bool, addressFilter string, includeImmatureCoinbase bool) ([]ReceivedByAddress, error) {
+	// Prepare parameters (use Bitcoin Core default minconf of 1 if not provided)
+	conf := 1
+	if minconf != nil {
+		conf = *minconf
+	}
+	params := []interface{}{conf, includeEmpty, includeWatchonly}
+
+	// Add address filter if provided (null keeps the filter disabled)
+	if addressFilter != "" || includeImmatureCoinbase {
+		if addressFilter != "" {
+			params = append(params, addressFilter)
+		} else {
+			params = append(params, nil)
+		}
+	}
+
+	// Add includeImmatureCoinbase if needed
+	if includeImmatureCoinbase {
+		params = append(params, includeImmatureCoinbase)
+	}
+
+	// Call the RPC method with wallet endpoint
+	resp, err := c.callWithWallet("listreceivedbyaddress", params, walletName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call listreceivedbyaddress: %v", err)
+	}
+
+	// Parse the result
+	var result ListReceivedByAddressResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal received by address list: %v", err)
+	}
+
+	return result, nil
+}
+
+// ListReceivedByLabel calls the listreceivedbylabel RPC method
+// walletName: name of the wallet to query
+// minconf: minimum number of confirmations before payments are included (optional, default 1)
+// includeEmpty: include labels that haven't received any payments
+// includeWatchonly: include watch-only addresses
+// includeImmatureCoinbase: include immature coinbase transactions
+func (c *Client) ListReceivedByLabel(walletName string, minconf *int, includeEmpty, includeWatchonly, includeImmatureCoinbase bool) ([]ReceivedByLabel, error) {
+	// Prepare parameters (use Bitcoin Core default minconf of 1 if not provided)
+	conf := 1
+	if minconf != nil {
+		conf = *minconf
+	}
+	params := []interface{}{conf, includeEmpty, includeWatchonly}
+
+	// Add includeImmatureCoinbase if needed
+	if includeImmatureCoinbase {
+		params = append(params, includeImmatureCoinbase)
+	}
+
+	// Call the RPC method with wallet endpoint
+	resp, err := c.callWithWallet("listreceivedbylabel", params, walletName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call listreceivedbylabel: %v", err)
+	}
+
+	// Parse the result
+	var result ListReceivedByLabelResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal received by label list: %v", err)
+	}
+
+	return result, nil
+}
+
+// GetReceivedByAddress calls the getreceivedbyaddress RPC method
+// walletName: name of the wallet owning the address
+// address: bitcoin address to get the received total for
+// minconf: only include transactions confirmed at least this many times (optional, default 1)
+// includeImmatureCoinbase: include immature coinbase transactions
+func (c *Client) GetReceivedByAddress(walletName, address string, minconf *int, includeImmatureCoinbase bool) (float64, error) {
+	// Prepare parameters
+	params := []interface{}{address}
+
+	// Add minconf if provided
+	if minconf != nil || includeImmatureCoinbase {
+		conf := 1
+		if minconf != nil {
+			conf = *minconf
+		}
+		params = append(params, conf)
+	}
+
+	// Add includeImmatureCoinbase if needed
+	if includeImmatureCoinbase {
+		params = append(params, includeImmatureCoinbase)
+	}
+
+	// Call the RPC method with wallet endpoint
+	resp, err := c.callWithWallet("getreceivedbyaddress", params, walletName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call getreceivedbyaddress: %v", err)
+	}
+
+	// Parse the result (getreceivedbyaddress returns a number directly)
+	var amount float64
+	if err := json.Unmarshal(resp.Result, &amount); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal received amount: %v", err)
+	}
+
+	return amount, nil
+}
+
+// ListSinceBlock calls the listsinceblock RPC method
+// walletName: name of the wallet to query
+// blockhash: return transactions since this block (empty for all transactions)
+// targetConfirmations: depth of the block whose hash is returned as lastblock (default 1)
+// includeWatchonly: include watch-only transactions
+// includeRemoved: include transactions removed by a reorg in the removed array
+func (c *Client) ListSinceBlock(walletName, blockhash string, targetConfirmations int, includeWatchonly, includeRemoved bool) (*ListSinceBlockResponse, error) {
+	// Add target confirmations (use Bitcoin Core default if not specified)
+	if targetConfirmations == 0 {
+		targetConfirmations = 1
+	}
+
+	// Prepare parameters
+	params := []interface{}{blockhash, targetConfirmations, includeWatchonly, includeRemoved}
+
+	// Call the RPC method with wallet endpoint
+	resp, err := c.callWithWallet("listsinceblock", params, walletName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call listsinceblock: %v", err)
+	}
+
+	// Parse the result
+	var result ListSinceBlockResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal list since block response: %v", err)
+	}
+
+	return &result, nil
+}
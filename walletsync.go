@@ -0,0 +1,170 @@
+package btcrpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WalletSyncEventType identifies the kind of change reported by a WalletSyncer
+type WalletSyncEventType int
+
+const (
+	// WalletTxAdded is emitted for a transaction seen for the first time or whose block changed
+	WalletTxAdded WalletSyncEventType = iota
+	// WalletTxRemoved is emitted for a transaction that was removed from the chain by a reorg
+	WalletTxRemoved
+)
+
+// String returns a readable name for the event type
+func (t WalletSyncEventType) String() string {
+	switch t {
+	case WalletTxAdded:
+		return "added"
+	case WalletTxRemoved:
+		return "removed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+// WalletSyncEvent is a single wallet transaction change
+type WalletSyncEvent struct {
+	Type        WalletSyncEventType
+	Transaction Transaction
+}
+
+// SyncCursorStore persists the listsinceblock cursor of a wallet between runs
+// LoadCursor returns an empty string when no cursor has been saved yet
+type SyncCursorStore interface {
+	LoadCursor(walletName string) (string, error)
+	SaveCursor(walletName, blockHash string) error
+}
+
+// MemoryCursorStore is an in-memory SyncCursorStore, mostly useful for tests and short-lived processes
+type MemoryCursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]string
+}
+
+// NewMemoryCursorStore creates an empty in-memory cursor store
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: make(map[string]string)}
+}
+
+// LoadCursor returns the saved cursor for walletName
+func (s *MemoryCursorStore) LoadCursor(walletName string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursors[walletName], nil
+}
+
+// SaveCursor stores the cursor for walletName
+func (s *MemoryCursorStore) SaveCursor(walletName, blockHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[walletName] = blockHash
+	return nil
+}
+
+// WalletSyncer incrementally follows wallet transactions with listsinceblock
+//
+// Each pass asks for everything since the stored cursor and stores the returned
+// lastblock, which sits TargetConfirmations blocks below the tip. The last
+// TargetConfirmations blocks are therefore read again on every pass, so a reorg
+// shallower than that is picked up without extra work; deeper reorgs are reported
+// through the removed array. Transactions already emitted with the same block are
+// not emitted again within a process, but after a restart the re-read window is
+// emitted once more, so handlers must be idempotent.
+type WalletSyncer struct {
+	client     *Client
+	walletName string
+	store      SyncCursorStore
+
+	// TargetConfirmations controls how far below the tip the cursor is kept (default 6)
+	TargetConfirmations int
+	// IncludeWatchonly includes watch-only transactions
+	IncludeWatchonly bool
+
+	seen map[string]string // txid:vout:category -> blockhash
+}
+
+// NewWalletSyncer creates a syncer for walletName that keeps its cursor in store
+func NewWalletSyncer(client *Client, walletName string, store SyncCursorStore) *WalletSyncer {
+	return &WalletSyncer{
+		client:              client,
+		walletName:          walletName,
+		store:               store,
+		TargetConfirmations: 6,
+		seen:                make(map[string]string),
+	}
+}
+
+// Sync performs one listsinceblock pass and passes every event to handle in order
+// The cursor is only advanced once handle has accepted all events of the pass
+func (s *WalletSyncer) Sync(handle func(WalletSyncEvent) error) error {
+	cursor, err := s.store.LoadCursor(s.walletName)
+	if err != nil {
+		return fmt.Errorf("failed to load sync cursor: %v", err)
+	}
+
+	result, err := s.client.ListSinceBlock(s.walletName, cursor, s.TargetConfirmations, s.IncludeWatchonly, true)
+	if err != nil {
+		return err
+	}
+
+	// Removed transactions go first so consumers can undo before re-applying
+	for _, tx := range result.Removed {
+		if err := handle(WalletSyncEvent{Type: WalletTxRemoved, Transaction: tx}); err != nil {
+			return err
+		}
+		delete(s.seen, walletTxKey(tx))
+	}
+
+	// Only keep the transactions of this pass so the seen set stays bounded by the re-read window
+	seen := make(map[string]string, len(result.Transactions))
+	for _, tx := range result.Transactions {
+		key := walletTxKey(tx)
+		seen[key] = tx.BlockHash
+		if blockHash, ok := s.seen[key]; ok && blockHash == tx.BlockHash {
+			continue
+		}
+		if err := handle(WalletSyncEvent{Type: WalletTxAdded, Transaction: tx}); err != nil {
+			return err
+		}
+		s.seen[key] = tx.BlockHash
+	}
+	s.seen = seen
+
+	if result.LastBlock != cursor {
+		if err := s.store.SaveCursor(s.walletName, result.LastBlock); err != nil {
+			return fmt.Errorf("failed to save sync cursor: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Run calls Sync every interval until ctx is cancelled or a pass fails
+func (s *WalletSyncer) Run(ctx context.Context, interval time.Duration, handle func(WalletSyncEvent) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(handle); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// walletTxKey identifies a wallet transaction entry, which is unique per txid, output and category
+func walletTxKey(tx Transaction) string {
+	return fmt.Sprintf("%s:%d:%s", tx.TxID, tx.Vout, tx.Category)
+}
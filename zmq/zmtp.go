@@ -0,0 +1,335 @@
+// Package zmq implements the subscriber side of ZMTP 3.0, the ZeroMQ wire protocol,
+// with the NULL security mechanism. It is enough to receive bitcoind's -zmqpub*
+// notifications without cgo or libzmq.
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxFrameSize bounds the size of a single frame accepted from the publisher
+// A raw block is at most 4 MB, so this leaves ample room.
+const MaxFrameSize = 32 << 20
+
+// handshakeTimeout bounds the greeting and READY exchange when ctx has no deadline
+const handshakeTimeout = 10 * time.Second
+
+// Frame flags
+const (
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+)
+
+// ErrClosed is returned by Recv after Close
+var ErrClosed = errors.New("zmq: connection closed")
+
+// Conn is a SUB socket connected to a single PUB endpoint
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	wmu    sync.Mutex
+	closed bool
+}
+
+// ParseEndpoint splits a ZeroMQ endpoint such as tcp://127.0.0.1:28332 into a network and address for net.Dial
+// Only the tcp and ipc transports are supported.
+func ParseEndpoint(endpoint string) (network, address string, err error) {
+	scheme, addr, ok := strings.Cut(endpoint, "://")
+	if !ok || addr == "" {
+		return "", "", fmt.Errorf("zmq: invalid endpoint %q", endpoint)
+	}
+	switch scheme {
+	case "tcp":
+		return "tcp", addr, nil
+	case "ipc":
+		return "unix", addr, nil
+	default:
+		return "", "", fmt.Errorf("zmq: unsupported transport %q", scheme)
+	}
+}
+
+// Dial connects to a PUB or XPUB endpoint and performs the ZMTP handshake
+func Dial(ctx context.Context, endpoint string) (*Conn, error) {
+	network, address, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("zmq: failed to dial %s: %w", endpoint, err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(handshakeTimeout)
+	}
+	nc.SetDeadline(deadline)
+
+	c := &Conn{conn: nc, r: bufio.NewReaderSize(nc, 64<<10)}
+	if err := c.handshake(); err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("zmq: handshake with %s failed: %w", endpoint, err)
+	}
+
+	nc.SetDeadline(time.Time{})
+	return c, nil
+}
+
+// Subscribe asks the publisher to send messages whose first frame starts with topic
+// An empty topic subscribes to everything.
+func (c *Conn) Subscribe(topic string) error {
+	// ZMTP 3.0 carries subscriptions as a message whose body is 0x01 followed by the prefix
+	body := append([]byte{0x01}, topic...)
+	return c.writeFrame(0, body)
+}
+
+// Unsubscribe cancels a previous Subscribe for topic
+func (c *Conn) Unsubscribe(topic string) error {
+	body := append([]byte{0x00}, topic...)
+	return c.writeFrame(0, body)
+}
+
+// Recv blocks until the next complete message and returns its frames
+// PING commands are answered transparently. Recv must not be called concurrently.
+func (c *Conn) Recv() ([][]byte, error) {
+	var parts [][]byte
+	for {
+		flags, body, err := c.readFrame()
+		if err != nil {
+			if c.isClosed() {
+				return nil, ErrClosed
+			}
+			return nil, err
+		}
+
+		if flags&flagCommand != 0 {
+			if err := c.handleCommand(body); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		parts = append(parts, body)
+		if flags&flagMore == 0 {
+			return parts, nil
+		}
+	}
+}
+
+// Close closes the connection, unblocking a pending Recv
+func (c *Conn) Close() error {
+	c.wmu.Lock()
+	c.closed = true
+	c.wmu.Unlock()
+	return c.conn.Close()
+}
+
+// handshake exchanges greetings and READY commands
+func (c *Conn) handshake() error {
+	// Signature, version 3.0, NULL mechanism, as-server false and filler
+	greeting := make([]byte, 64)
+	greeting[0] = 0xff
+	greeting[9] = 0x7f
+	greeting[10] = 3
+	greeting[11] = 0
+	copy(greeting[12:32], "NULL")
+	if _, err := c.conn.Write(greeting); err != nil {
+		return err
+	}
+
+	peer := make([]byte, 64)
+	if _, err := io.ReadFull(c.r, peer); err != nil {
+		return fmt.Errorf("failed to read greeting: %w", err)
+	}
+	if peer[0] != 0xff || peer[9] != 0x7f {
+		return errors.New("peer does not speak ZMTP")
+	}
+	if peer[10] < 3 {
+		return fmt.Errorf("unsupported ZMTP version %d.%d", peer[10], peer[11])
+	}
+	if mechanism := string(bytes.TrimRight(peer[12:32], "\x00")); mechanism != "NULL" {
+		return fmt.Errorf("unsupported security mechanism %q", mechanism)
+	}
+
+	if err := c.writeFrame(flagCommand, command("READY", metadata("Socket-Type", "SUB"))); err != nil {
+		return err
+	}
+
+	flags, body, err := c.readFrame()
+	if err != nil {
+		return fmt.Errorf("failed to read READY: %w", err)
+	}
+	if flags&flagCommand == 0 {
+		return errors.New("expected READY command")
+	}
+	name, data, err := parseCommand(body)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "READY":
+	case "ERROR":
+		return fmt.Errorf("peer refused connection: %s", errorReason(data))
+	default:
+		return fmt.Errorf("expected READY command, got %s", name)
+	}
+
+	props, err := parseMetadata(data)
+	if err != nil {
+		return err
+	}
+	if socketType := props["socket-type"]; socketType != "PUB" && socketType != "XPUB" {
+		return fmt.Errorf("peer socket type %q is not PUB or XPUB", socketType)
+	}
+	return nil
+}
+
+// handleCommand answers PING and turns ERROR into an error, other commands are ignored
+func (c *Conn) handleCommand(body []byte) error {
+	name, data, err := parseCommand(body)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "PING":
+		// PING carries a 2 byte TTL followed by a context that PONG echoes
+		if len(data) < 2 {
+			return errors.New("zmq: malformed PING")
+		}
+		return c.writeFrame(flagCommand, command("PONG", data[2:]))
+	case "ERROR":
+		return fmt.Errorf("zmq: peer error: %s", errorReason(data))
+	}
+	return nil
+}
+
+// readFrame reads one frame
+func (c *Conn) readFrame() (byte, []byte, error) {
+	flags, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var size uint64
+	if flags&flagLong != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(c.r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(b)
+	}
+	if size > MaxFrameSize {
+		return 0, nil, fmt.Errorf("zmq: frame of %d bytes exceeds the %d byte limit", size, MaxFrameSize)
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+// writeFrame writes one frame, using the long size form when needed
+func (c *Conn) writeFrame(flags byte, body []byte) error {
+	var buf []byte
+	if len(body) > 255 {
+		buf = make([]byte, 9, 9+len(body))
+		buf[0] = flags | flagLong
+		binary.BigEndian.PutUint64(buf[1:], uint64(len(body)))
+	} else {
+		buf = make([]byte, 2, 2+len(body))
+		buf[0] = flags
+		buf[1] = byte(len(body))
+	}
+	buf = append(buf, body...)
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+func (c *Conn) isClosed() bool {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.closed
+}
+
+// command builds a command frame body
+func command(name string, data []byte) []byte {
+	body := make([]byte, 0, 1+len(name)+len(data))
+	body = append(body, byte(len(name)))
+	body = append(body, name...)
+	return append(body, data...)
+}
+
+// parseCommand splits a command frame body into its name and data
+func parseCommand(body []byte) (string, []byte, error) {
+	if len(body) < 1 || len(body) < 1+int(body[0]) {
+		return "", nil, errors.New("zmq: malformed command")
+	}
+	n := int(body[0])
+	return string(body[1 : 1+n]), body[1+n:], nil
+}
+
+// metadata encodes a single property as used in READY
+func metadata(name, value string) []byte {
+	buf := make([]byte, 0, 1+len(name)+4+len(value))
+	buf = append(buf, byte(len(name)))
+	buf = append(buf, name...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(value)))
+	return append(buf, value...)
+}
+
+// parseMetadata decodes READY properties, with names lowercased since they are case-insensitive
+func parseMetadata(data []byte) (map[string]string, error) {
+	props := make(map[string]string)
+	for len(data) > 0 {
+		n := int(data[0])
+		if len(data) < 1+n+4 {
+			return nil, errors.New("zmq: malformed metadata")
+		}
+		name := strings.ToLower(string(data[1 : 1+n]))
+		data = data[1+n:]
+
+		size := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint64(len(data)) < uint64(size) {
+			return nil, errors.New("zmq: malformed metadata")
+		}
+		props[name] = string(data[:size])
+		data = data[size:]
+	}
+	return props, nil
+}
+
+// errorReason extracts the reason of an ERROR command
+func errorReason(data []byte) string {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return "unknown"
+	}
+	return string(data[1 : 1+int(data[0])])
+}
@@ -0,0 +1,338 @@
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// publisher is a stand-in PUB socket that speaks just enough ZMTP 3.0 to drive a Conn
+// Frames are encoded here independently of the code under test.
+type publisher struct {
+	ln   net.Listener
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// newPublisher listens on a loopback port
+func newPublisher(t *testing.T) *publisher {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	p := &publisher{ln: ln}
+	t.Cleanup(func() {
+		ln.Close()
+		if p.conn != nil {
+			p.conn.Close()
+		}
+	})
+	return p
+}
+
+// endpoint returns the ZeroMQ endpoint of the listener
+func (p *publisher) endpoint() string {
+	return "tcp://" + p.ln.Addr().String()
+}
+
+// accept takes one connection and completes the handshake announcing socketType
+func (p *publisher) accept(socketType string) error {
+	conn, err := p.ln.Accept()
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	p.conn = conn
+	p.r = bufio.NewReader(conn)
+
+	greeting := make([]byte, 64)
+	if _, err := io.ReadFull(p.r, greeting); err != nil {
+		return fmt.Errorf("read greeting: %w", err)
+	}
+	switch {
+	case greeting[0] != 0xff || greeting[9] != 0x7f:
+		return errors.New("greeting has no ZMTP signature")
+	case greeting[10] != 3:
+		return fmt.Errorf("greeting has version %d", greeting[10])
+	case string(bytes.TrimRight(greeting[12:32], "\x00")) != "NULL":
+		return fmt.Errorf("greeting has mechanism %q", greeting[12:32])
+	case greeting[32] != 0:
+		return errors.New("subscriber claims to be the server")
+	}
+
+	reply := make([]byte, 64)
+	reply[0], reply[9], reply[10] = 0xff, 0x7f, 3
+	copy(reply[12:], "NULL")
+	reply[32] = 1
+	if _, err := conn.Write(reply); err != nil {
+		return err
+	}
+
+	flags, body, err := p.readFrame()
+	if err != nil {
+		return fmt.Errorf("read READY: %w", err)
+	}
+	if flags != flagCommand || !bytes.HasPrefix(body, []byte("\x05READY")) {
+		return fmt.Errorf("expected READY, got flags %#x body %q", flags, body)
+	}
+	if !bytes.Contains(body, []byte("\x0bSocket-Type\x00\x00\x00\x03SUB")) {
+		return fmt.Errorf("READY does not announce a SUB socket: %q", body)
+	}
+
+	ready := []byte("\x05READY\x0bSocket-Type")
+	ready = binary.BigEndian.AppendUint32(ready, uint32(len(socketType)))
+	ready = append(ready, socketType...)
+	return p.writeFrame(flagCommand, ready)
+}
+
+// readFrame reads one frame from the subscriber
+func (p *publisher) readFrame() (byte, []byte, error) {
+	flags, err := p.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(p.r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		b, err := p.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(b)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(p.r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+// writeFrame writes one frame to the subscriber
+func (p *publisher) writeFrame(flags byte, body []byte) error {
+	var header []byte
+	if len(body) > 255 {
+		header = binary.BigEndian.AppendUint64([]byte{flags | flagLong}, uint64(len(body)))
+	} else {
+		header = []byte{flags, byte(len(body))}
+	}
+	_, err := p.conn.Write(append(header, body...))
+	return err
+}
+
+// publish writes a multipart message
+func (p *publisher) publish(parts ...[]byte) error {
+	for i, part := range parts {
+		var flags byte
+		if i < len(parts)-1 {
+			flags = flagMore
+		}
+		if err := p.writeFrame(flags, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dial connects a Conn to p while p accepts it as socketType
+func dial(t *testing.T, p *publisher, socketType string) (*Conn, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accepted := make(chan error, 1)
+	go func() { accepted <- p.accept(socketType) }()
+
+	conn, err := Dial(ctx, p.endpoint())
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := <-accepted; err != nil {
+		t.Fatalf("publisher handshake: %v", err)
+	}
+	return conn, nil
+}
+
+func TestDialHandshake(t *testing.T) {
+	for _, socketType := range []string{"PUB", "XPUB"} {
+		p := newPublisher(t)
+		if _, err := dial(t, p, socketType); err != nil {
+			t.Fatalf("dial %s: %v", socketType, err)
+		}
+	}
+}
+
+func TestDialRejectsNonPublisher(t *testing.T) {
+	p := newPublisher(t)
+	accepted := make(chan error, 1)
+	go func() { accepted <- p.accept("REP") }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := Dial(ctx, p.endpoint())
+	<-accepted
+	if err == nil {
+		conn.Close()
+		t.Fatal("dial succeeded against a REP socket")
+	}
+	if !strings.Contains(err.Error(), `"REP" is not PUB or XPUB`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSubscribeFrames(t *testing.T) {
+	p := newPublisher(t)
+	conn, err := dial(t, p, "PUB")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	if err := conn.Subscribe("rawtx"); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := conn.Unsubscribe("rawtx"); err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+
+	for _, want := range []string{"\x01rawtx", "\x00rawtx"} {
+		flags, body, err := p.readFrame()
+		if err != nil {
+			t.Fatalf("read frame: %v", err)
+		}
+		if flags != 0 || string(body) != want {
+			t.Fatalf("got flags %#x body %q, want a message frame %q", flags, body, want)
+		}
+	}
+}
+
+func TestRecvMultipart(t *testing.T) {
+	p := newPublisher(t)
+	conn, err := dial(t, p, "PUB")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	// The body needs the long size form
+	body := bytes.Repeat([]byte{0xab}, 1000)
+	if err := p.publish([]byte("rawtx"), body, []byte{1, 0, 0, 0}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	parts, err := conn.Recv()
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	if len(parts) != 3 || string(parts[0]) != "rawtx" || !bytes.Equal(parts[1], body) || !bytes.Equal(parts[2], []byte{1, 0, 0, 0}) {
+		t.Fatalf("unexpected parts %q", parts)
+	}
+}
+
+func TestRecvAnswersPing(t *testing.T) {
+	p := newPublisher(t)
+	conn, err := dial(t, p, "PUB")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	// TTL of 100 deciseconds followed by a context the PONG must echo
+	if err := p.writeFrame(flagCommand, []byte("\x04PING\x00\x64ctx")); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if err := p.publish([]byte("hashblock"), []byte("after ping")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	parts, err := conn.Recv()
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	if len(parts) != 2 || string(parts[1]) != "after ping" {
+		t.Fatalf("unexpected parts %q", parts)
+	}
+
+	flags, body, err := p.readFrame()
+	if err != nil {
+		t.Fatalf("read pong: %v", err)
+	}
+	if flags != flagCommand || string(body) != "\x04PONGctx" {
+		t.Fatalf("got flags %#x body %q, want PONG echoing the context", flags, body)
+	}
+}
+
+func TestRecvRejectsOversizedFrame(t *testing.T) {
+	p := newPublisher(t)
+	conn, err := dial(t, p, "PUB")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	// Only the header is sent, the size alone must be refused
+	header := binary.BigEndian.AppendUint64([]byte{flagLong}, MaxFrameSize+1)
+	if _, err := p.conn.Write(header); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	_, err = conn.Recv()
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("expected a frame size error, got %v", err)
+	}
+}
+
+func TestRecvAfterClose(t *testing.T) {
+	p := newPublisher(t)
+	conn, err := dial(t, p, "PUB")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := conn.Recv()
+		result <- err
+	}()
+	conn.Close()
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Recv still blocked after Close")
+	}
+}
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		network  string
+		address  string
+		wantErr  bool
+	}{
+		{"tcp://127.0.0.1:28332", "tcp", "127.0.0.1:28332", false},
+		{"ipc:///tmp/bitcoind.sock", "unix", "/tmp/bitcoind.sock", false},
+		{"udp://127.0.0.1:28332", "", "", true},
+		{"127.0.0.1:28332", "", "", true},
+		{"tcp://", "", "", true},
+	}
+	for _, tt := range tests {
+		network, address, err := ParseEndpoint(tt.endpoint)
+		if (err != nil) != tt.wantErr || network != tt.network || address != tt.address {
+			t.Errorf("ParseEndpoint(%q) = %q, %q, %v", tt.endpoint, network, address, err)
+		}
+	}
+}
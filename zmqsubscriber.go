@@ -0,0 +1,444 @@
+package btcrpc
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koinvote/btcrpc/zmq"
+)
+
+// ZMQ topics published by bitcoind
+const (
+	ZMQTopicHashBlock = "hashblock"
+	ZMQTopicRawBlock  = "rawblock"
+	ZMQTopicHashTx    = "hashtx"
+	ZMQTopicRawTx     = "rawtx"
+	ZMQTopicSequence  = "sequence"
+)
+
+// ZMQConfig lists the endpoints of the topics to subscribe to, as set with bitcoind's -zmqpub* options
+// An empty endpoint leaves the topic unsubscribed. Topics sharing an endpoint share a connection.
+type ZMQConfig struct {
+	HashBlock string // e.g. tcp://127.0.0.1:28332
+	RawBlock  string
+	HashTx    string
+	RawTx     string
+	Sequence  string
+
+	// Buffer is the capacity of each notification channel (default 1000)
+	Buffer int
+	// ReconnectInterval is the initial delay before redialing a lost endpoint, doubled up to 30s (default 1s)
+	ReconnectInterval time.Duration
+}
+
+// ZMQHash is a hashblock or hashtx notification
+type ZMQHash struct {
+	Hash     string
+	Sequence uint32 // per-topic message counter
+}
+
+// ZMQRaw is a rawblock or rawtx notification
+type ZMQRaw struct {
+	Data     []byte // serialized block or transaction
+	Sequence uint32
+}
+
+// ZMQSequenceEventType identifies the kind of a sequence notification
+type ZMQSequenceEventType int
+
+const (
+	// ZMQBlockConnected is a block connected to the active chain
+	ZMQBlockConnected ZMQSequenceEventType = iota
+	// ZMQBlockDisconnected is a block disconnected from the active chain
+	ZMQBlockDisconnected
+	// ZMQTxAdded is a transaction added to the mempool
+	ZMQTxAdded
+	// ZMQTxRemoved is a transaction removed from the mempool for any reason other than block inclusion
+	ZMQTxRemoved
+)
+
+// String returns a readable name for the event type
+func (t ZMQSequenceEventType) String() string {
+	switch t {
+	case ZMQBlockConnected:
+		return "block connected"
+	case ZMQBlockDisconnected:
+		return "block disconnected"
+	case ZMQTxAdded:
+		return "tx added"
+	case ZMQTxRemoved:
+		return "tx removed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+// ZMQSequenceEvent is a sequence notification
+type ZMQSequenceEvent struct {
+	Type            ZMQSequenceEventType
+	Hash            string // block hash or txid
+	MempoolSequence uint64 // only set for ZMQTxAdded and ZMQTxRemoved
+	Sequence        uint32
+}
+
+// ZMQGap records messages missing from a topic
+type ZMQGap struct {
+	Topic    string
+	Expected uint32 // sequence number that should have arrived
+	Received uint32 // sequence number that arrived instead
+}
+
+// ZMQResync is emitted once the subscriber is connected and again after messages were lost
+//
+// Mempool is a fresh getrawmempool snapshot. Sequence notifications whose MempoolSequence
+// is not above Mempool.MempoolSequence are already reflected in it and can be skipped.
+type ZMQResync struct {
+	Gaps    []ZMQGap // empty for the initial snapshot
+	Mempool *GetRawMempoolSequenceResponse
+	Err     error // set when the snapshot could not be fetched
+}
+
+// ZMQSubscriber receives bitcoind ZMQ notifications and delivers them on typed channels
+//
+// Every topic carries a sequence number that bitcoind increments per message. A jump
+// means the publisher dropped messages, usually because the consumer fell behind, and
+// is reported on Resyncs together with a mempool snapshot taken over RPC, so mempool
+// state can be rebuilt. Lost connections are redialed; the sequence numbers reveal
+// whatever was published meanwhile. Channels of unconfigured topics are nil.
+type ZMQSubscriber struct {
+	HashBlocks <-chan ZMQHash
+	RawBlocks  <-chan ZMQRaw
+	HashTxs    <-chan ZMQHash
+	RawTxs     <-chan ZMQRaw
+	Sequence   <-chan ZMQSequenceEvent
+	Resyncs    <-chan ZMQResync
+
+	client *Client
+	config ZMQConfig
+
+	hashBlocks chan ZMQHash
+	rawBlocks  chan ZMQRaw
+	hashTxs    chan ZMQHash
+	rawTxs     chan ZMQRaw
+	sequence   chan ZMQSequenceEvent
+	resyncs    chan ZMQResync
+
+	mu      sync.Mutex
+	last    map[string]uint32
+	gaps    []ZMQGap
+	pending chan struct{}
+}
+
+// NewZMQSubscriber creates a subscriber for the endpoints in config
+// client is used for mempool snapshots; with a nil client resyncs carry no snapshot.
+func NewZMQSubscriber(client *Client, config ZMQConfig) *ZMQSubscriber {
+	if config.Buffer <= 0 {
+		config.Buffer = 1000
+	}
+	if config.ReconnectInterval <= 0 {
+		config.ReconnectInterval = time.Second
+	}
+
+	s := &ZMQSubscriber{
+		client:  client,
+		config:  config,
+		resyncs: make(chan ZMQResync, 16),
+		last:    make(map[string]uint32),
+		pending: make(chan struct{}, 1),
+	}
+	if config.HashBlock != "" {
+		s.hashBlocks = make(chan ZMQHash, config.Buffer)
+		s.HashBlocks = s.hashBlocks
+	}
+	if config.RawBlock != "" {
+		s.rawBlocks = make(chan ZMQRaw, config.Buffer)
+		s.RawBlocks = s.rawBlocks
+	}
+	if config.HashTx != "" {
+		s.hashTxs = make(chan ZMQHash, config.Buffer)
+		s.HashTxs = s.hashTxs
+	}
+	if config.RawTx != "" {
+		s.rawTxs = make(chan ZMQRaw, config.Buffer)
+		s.RawTxs = s.rawTxs
+	}
+	if config.Sequence != "" {
+		s.sequence = make(chan ZMQSequenceEvent, config.Buffer)
+		s.Sequence = s.sequence
+	}
+	s.Resyncs = s.resyncs
+	return s
+}
+
+// Run connects to every endpoint and delivers notifications until ctx is cancelled
+// All channels are closed when Run returns. Run must only be called once.
+func (s *ZMQSubscriber) Run(ctx context.Context) error {
+	defer s.closeChannels()
+
+	endpoints := make(map[string][]string)
+	for _, t := range []struct{ topic, endpoint string }{
+		{ZMQTopicHashBlock, s.config.HashBlock},
+		{ZMQTopicRawBlock, s.config.RawBlock},
+		{ZMQTopicHashTx, s.config.HashTx},
+		{ZMQTopicRawTx, s.config.RawTx},
+		{ZMQTopicSequence, s.config.Sequence},
+	} {
+		if t.endpoint == "" {
+			continue
+		}
+		if _, _, err := zmq.ParseEndpoint(t.endpoint); err != nil {
+			return err
+		}
+		endpoints[t.endpoint] = append(endpoints[t.endpoint], t.topic)
+	}
+	if len(endpoints) == 0 {
+		return fmt.Errorf("no ZMQ endpoints configured")
+	}
+
+	var wg sync.WaitGroup
+	var connected sync.WaitGroup
+	connected.Add(len(endpoints))
+	for endpoint, topics := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.subscribe(ctx, endpoint, topics, connected.Done)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.resyncLoop(ctx, &connected)
+	}()
+
+	wg.Wait()
+	return ctx.Err()
+}
+
+// subscribe keeps one endpoint connected and dispatches its messages
+// ready is called after the first successful subscription.
+func (s *ZMQSubscriber) subscribe(ctx context.Context, endpoint string, topics []string, ready func()) {
+	var once sync.Once
+	defer once.Do(ready)
+
+	backoff := s.config.ReconnectInterval
+	for ctx.Err() == nil {
+		conn, err := s.connect(ctx, endpoint, topics)
+		if err == nil {
+			once.Do(ready)
+			backoff = s.config.ReconnectInterval
+
+			stop := context.AfterFunc(ctx, func() { conn.Close() })
+			s.receive(ctx, conn)
+			stop()
+			conn.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// connect dials endpoint and subscribes to topics
+func (s *ZMQSubscriber) connect(ctx context.Context, endpoint string, topics []string) (*zmq.Conn, error) {
+	conn, err := zmq.Dial(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	for _, topic := range topics {
+		if err := conn.Subscribe(topic); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// receive dispatches messages until the connection fails
+func (s *ZMQSubscriber) receive(ctx context.Context, conn *zmq.Conn) {
+	for {
+		parts, err := conn.Recv()
+		if err != nil {
+			return
+		}
+		// bitcoind sends topic, body and a 4 byte little-endian sequence number
+		if len(parts) != 3 || len(parts[2]) != 4 {
+			continue
+		}
+		topic, body := string(parts[0]), parts[1]
+		seq := binary.LittleEndian.Uint32(parts[2])
+		s.track(topic, seq)
+
+		if !s.dispatch(ctx, topic, body, seq) {
+			return
+		}
+	}
+}
+
+// dispatch decodes a message and delivers it, returning false once ctx is done
+func (s *ZMQSubscriber) dispatch(ctx context.Context, topic string, body []byte, seq uint32) bool {
+	switch topic {
+	case ZMQTopicHashBlock, ZMQTopicHashTx:
+		if len(body) != 32 {
+			return true
+		}
+		// The hash is sent in display byte order, as used by RPC
+		n := ZMQHash{Hash: hex.EncodeToString(body), Sequence: seq}
+		if topic == ZMQTopicHashBlock {
+			return send(ctx, s.hashBlocks, n)
+		}
+		return send(ctx, s.hashTxs, n)
+
+	case ZMQTopicRawBlock:
+		return send(ctx, s.rawBlocks, ZMQRaw{Data: body, Sequence: seq})
+
+	case ZMQTopicRawTx:
+		return send(ctx, s.rawTxs, ZMQRaw{Data: body, Sequence: seq})
+
+	case ZMQTopicSequence:
+		// 32 byte hash, a label and, for mempool events, an 8 byte little-endian mempool sequence
+		if len(body) < 33 {
+			return true
+		}
+		event := ZMQSequenceEvent{Hash: hex.EncodeToString(body[:32]), Sequence: seq}
+		switch body[32] {
+		case 'C':
+			event.Type = ZMQBlockConnected
+		case 'D':
+			event.Type = ZMQBlockDisconnected
+		case 'A', 'R':
+			if len(body) != 41 {
+				return true
+			}
+			event.Type = ZMQTxAdded
+			if body[32] == 'R' {
+				event.Type = ZMQTxRemoved
+			}
+			event.MempoolSequence = binary.LittleEndian.Uint64(body[33:])
+		default:
+			return true
+		}
+		return send(ctx, s.sequence, event)
+	}
+	return true
+}
+
+// track checks seq against the previous message of topic and schedules a resync on a gap
+func (s *ZMQSubscriber) track(topic string, seq uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, seen := s.last[topic]
+	s.last[topic] = seq
+	if !seen || seq == last+1 {
+		return
+	}
+
+	s.gaps = append(s.gaps, ZMQGap{Topic: topic, Expected: last + 1, Received: seq})
+	select {
+	case s.pending <- struct{}{}:
+	default:
+	}
+}
+
+// resyncLoop emits the initial snapshot once all endpoints are subscribed, then one per batch of gaps
+// Gaps found while a snapshot is being taken are folded into the next one.
+func (s *ZMQSubscriber) resyncLoop(ctx context.Context, connected *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		connected.Wait()
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return
+	case <-done:
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Gaps seen before the initial snapshot are covered by it
+	s.mu.Lock()
+	s.gaps = nil
+	s.mu.Unlock()
+	select {
+	case <-s.pending:
+	default:
+	}
+	if !send(ctx, s.resyncs, s.snapshot(nil)) {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.pending:
+		}
+
+		s.mu.Lock()
+		gaps := s.gaps
+		s.gaps = nil
+		s.mu.Unlock()
+		if len(gaps) == 0 {
+			continue
+		}
+
+		if !send(ctx, s.resyncs, s.snapshot(gaps)) {
+			return
+		}
+	}
+}
+
+// snapshot fetches the mempool over RPC
+func (s *ZMQSubscriber) snapshot(gaps []ZMQGap) ZMQResync {
+	resync := ZMQResync{Gaps: gaps}
+	if s.client == nil {
+		return resync
+	}
+	resync.Mempool, resync.Err = s.client.GetRawMempoolWithSequence()
+	return resync
+}
+
+// closeChannels closes every notification channel
+func (s *ZMQSubscriber) closeChannels() {
+	if s.hashBlocks != nil {
+		close(s.hashBlocks)
+	}
+	if s.rawBlocks != nil {
+		close(s.rawBlocks)
+	}
+	if s.hashTxs != nil {
+		close(s.hashTxs)
+	}
+	if s.rawTxs != nil {
+		close(s.rawTxs)
+	}
+	if s.sequence != nil {
+		close(s.sequence)
+	}
+	close(s.resyncs)
+}
+
+// send delivers v on ch unless ctx is done first, messages for a nil channel are dropped
+func send[T any](ctx context.Context, ch chan<- T, v T) bool {
+	if ch == nil {
+		return true
+	}
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,340 @@
+package btcrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testTimeout bounds every wait in the subscriber tests
+const testTimeout = 5 * time.Second
+
+// zmqPublisher is a stand-in bitcoind ZMQ publisher that accepts any number of subscribers
+type zmqPublisher struct {
+	ln    net.Listener
+	peers chan *zmqPeer
+}
+
+// zmqPeer is one accepted subscriber connection
+type zmqPeer struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// newZMQPublisher listens on a loopback port and completes the ZMTP handshake with every subscriber as a PUB socket
+func newZMQPublisher(t *testing.T) *zmqPublisher {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	p := &zmqPublisher{ln: ln, peers: make(chan *zmqPeer, 4)}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			peer := &zmqPeer{conn: conn, r: bufio.NewReader(conn)}
+			if err := peer.handshake(); err != nil {
+				conn.Close()
+				continue
+			}
+			p.peers <- peer
+		}
+	}()
+	return p
+}
+
+// endpoint returns the ZeroMQ endpoint of the publisher
+func (p *zmqPublisher) endpoint() string {
+	return "tcp://" + p.ln.Addr().String()
+}
+
+// accept waits for the next subscriber and reads its subscriptions
+func (p *zmqPublisher) accept(t *testing.T, topics ...string) *zmqPeer {
+	t.Helper()
+	var peer *zmqPeer
+	select {
+	case peer = <-p.peers:
+	case <-time.After(testTimeout):
+		t.Fatal("no subscriber connected")
+	}
+	t.Cleanup(func() { peer.conn.Close() })
+
+	var subscribed []string
+	for range topics {
+		flags, body, err := peer.readFrame()
+		if err != nil {
+			t.Fatalf("read subscription: %v", err)
+		}
+		if flags != 0 || len(body) == 0 || body[0] != 0x01 {
+			t.Fatalf("expected a SUBSCRIBE message, got flags %#x body %q", flags, body)
+		}
+		subscribed = append(subscribed, string(body[1:]))
+	}
+	slices.Sort(subscribed)
+	slices.Sort(topics)
+	if !slices.Equal(subscribed, topics) {
+		t.Fatalf("subscribed to %q, want %q", subscribed, topics)
+	}
+	return peer
+}
+
+// handshake exchanges greetings and READY commands as a PUB socket
+func (p *zmqPeer) handshake() error {
+	p.conn.SetDeadline(time.Now().Add(testTimeout))
+	defer p.conn.SetDeadline(time.Time{})
+
+	greeting := make([]byte, 64)
+	if _, err := io.ReadFull(p.r, greeting); err != nil {
+		return err
+	}
+	reply := make([]byte, 64)
+	reply[0], reply[9], reply[10] = 0xff, 0x7f, 3
+	copy(reply[12:], "NULL")
+	reply[32] = 1
+	if _, err := p.conn.Write(reply); err != nil {
+		return err
+	}
+
+	if _, _, err := p.readFrame(); err != nil {
+		return err
+	}
+	return p.writeFrame(0x04, []byte("\x05READY\x0bSocket-Type\x00\x00\x00\x03PUB"))
+}
+
+// readFrame reads one short frame, which is all a subscriber sends here
+func (p *zmqPeer) readFrame() (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(p.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	body := make([]byte, header[1])
+	_, err := io.ReadFull(p.r, body)
+	return header[0], body, err
+}
+
+// writeFrame writes one short frame
+func (p *zmqPeer) writeFrame(flags byte, body []byte) error {
+	_, err := p.conn.Write(append([]byte{flags, byte(len(body))}, body...))
+	return err
+}
+
+// publish sends a notification the way bitcoind does: topic, body and little-endian sequence number
+func (p *zmqPeer) publish(t *testing.T, topic string, body []byte, seq uint32) {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.Write([]byte{0x01, byte(len(topic))})
+	buf.WriteString(topic)
+	if len(body) > 255 {
+		buf.WriteByte(0x01 | 0x02)
+		binary.Write(&buf, binary.BigEndian, uint64(len(body)))
+	} else {
+		buf.Write([]byte{0x01, byte(len(body))})
+	}
+	buf.Write(body)
+	buf.Write([]byte{0x00, 4})
+	binary.Write(&buf, binary.LittleEndian, seq)
+	if _, err := p.conn.Write(buf.Bytes()); err != nil {
+		t.Fatalf("publish %s: %v", topic, err)
+	}
+}
+
+// newMempoolServer serves getrawmempool with mempool_sequence and counts the calls
+func newMempoolServer(t *testing.T) (*Client, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req RPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Method != "getrawmempool" || len(req.Params) != 2 || req.Params[0] != false || req.Params[1] != true {
+			http.Error(w, fmt.Sprintf("unexpected call %s %v", req.Method, req.Params), http.StatusBadRequest)
+			return
+		}
+		n := calls.Add(1)
+		fmt.Fprintf(w, `{"result":{"txids":["%064x"],"mempool_sequence":%d},"error":null,"id":%d}`, n, 100*n, req.ID)
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, "user", "pass"), &calls
+}
+
+// runSubscriber starts s and stops it when the test ends, checking that Run returns and closes its channels
+func runSubscriber(t *testing.T, s *ZMQSubscriber) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(testTimeout):
+			t.Error("Run did not return after cancel")
+			return
+		}
+		for range s.Resyncs {
+		}
+	})
+}
+
+// receive waits for the next value on ch
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed")
+		}
+		return v
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for a notification")
+	}
+	panic("unreachable")
+}
+
+// receiveGaps collects resyncs until n gaps have been reported, checking each carries a snapshot
+func receiveGaps(t *testing.T, resyncs <-chan ZMQResync, n int) []ZMQGap {
+	t.Helper()
+	var gaps []ZMQGap
+	for len(gaps) < n {
+		resync := receive(t, resyncs)
+		if resync.Err != nil {
+			t.Fatalf("resync failed: %v", resync.Err)
+		}
+		if len(resync.Gaps) == 0 || resync.Mempool == nil {
+			t.Fatalf("expected a resync with gaps and a snapshot, got %+v", resync)
+		}
+		gaps = append(gaps, resync.Gaps...)
+	}
+	return gaps
+}
+
+func TestZMQSubscriberDeliversNotifications(t *testing.T) {
+	client, calls := newMempoolServer(t)
+	pub := newZMQPublisher(t)
+	s := NewZMQSubscriber(client, ZMQConfig{
+		HashBlock: pub.endpoint(),
+		RawTx:     pub.endpoint(),
+		Sequence:  pub.endpoint(),
+	})
+	if s.RawBlocks != nil || s.HashTxs != nil {
+		t.Fatal("channels of unconfigured topics must be nil")
+	}
+	runSubscriber(t, s)
+
+	// Topics on one endpoint share a connection
+	peer := pub.accept(t, ZMQTopicHashBlock, ZMQTopicRawTx, ZMQTopicSequence)
+
+	initial := receive(t, s.Resyncs)
+	if len(initial.Gaps) != 0 || initial.Err != nil || initial.Mempool == nil || initial.Mempool.MempoolSequence != 100 {
+		t.Fatalf("unexpected initial resync %+v", initial)
+	}
+
+	hash := bytes.Repeat([]byte{0x11}, 32)
+	peer.publish(t, ZMQTopicHashBlock, hash, 7)
+	if n := receive(t, s.HashBlocks); n.Hash != hex.EncodeToString(hash) || n.Sequence != 7 {
+		t.Fatalf("unexpected hashblock %+v", n)
+	}
+
+	rawtx := bytes.Repeat([]byte{0x22}, 300)
+	peer.publish(t, ZMQTopicRawTx, rawtx, 0)
+	if n := receive(t, s.RawTxs); !bytes.Equal(n.Data, rawtx) || n.Sequence != 0 {
+		t.Fatalf("unexpected rawtx %+v", n)
+	}
+
+	added := append(bytes.Repeat([]byte{0x33}, 32), 'A')
+	added = binary.LittleEndian.AppendUint64(added, 101)
+	peer.publish(t, ZMQTopicSequence, added, 0)
+	event := receive(t, s.Sequence)
+	if event.Type != ZMQTxAdded || event.MempoolSequence != 101 || event.Hash != hex.EncodeToString(added[:32]) {
+		t.Fatalf("unexpected sequence event %+v", event)
+	}
+
+	connected := append(bytes.Repeat([]byte{0x44}, 32), 'C')
+	peer.publish(t, ZMQTopicSequence, connected, 1)
+	if event := receive(t, s.Sequence); event.Type != ZMQBlockConnected || event.Sequence != 1 {
+		t.Fatalf("unexpected sequence event %+v", event)
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("getrawmempool called %d times without a gap, want 1", n)
+	}
+}
+
+func TestZMQSubscriberResyncsOnGap(t *testing.T) {
+	client, calls := newMempoolServer(t)
+	pub := newZMQPublisher(t)
+	s := NewZMQSubscriber(client, ZMQConfig{RawTx: pub.endpoint(), Sequence: pub.endpoint()})
+	runSubscriber(t, s)
+
+	peer := pub.accept(t, ZMQTopicRawTx, ZMQTopicSequence)
+	receive(t, s.Resyncs)
+
+	removed := binary.LittleEndian.AppendUint64(append(bytes.Repeat([]byte{0x55}, 32), 'R'), 7)
+	for _, seq := range []uint32{0, 1, 5} {
+		peer.publish(t, ZMQTopicSequence, removed, seq)
+		receive(t, s.Sequence)
+	}
+	for _, seq := range []uint32{10, 11, 14} {
+		peer.publish(t, ZMQTopicRawTx, []byte{0x01}, seq)
+		receive(t, s.RawTxs)
+	}
+
+	gaps := receiveGaps(t, s.Resyncs, 2)
+	want := []ZMQGap{
+		{Topic: ZMQTopicSequence, Expected: 2, Received: 5},
+		{Topic: ZMQTopicRawTx, Expected: 12, Received: 14},
+	}
+	if !slices.Equal(gaps, want) {
+		t.Fatalf("got gaps %+v, want %+v", gaps, want)
+	}
+	if n := calls.Load(); n < 2 {
+		t.Fatalf("getrawmempool called %d times, want a snapshot per resync", n)
+	}
+}
+
+func TestZMQSubscriberReconnects(t *testing.T) {
+	client, _ := newMempoolServer(t)
+	pub := newZMQPublisher(t)
+	s := NewZMQSubscriber(client, ZMQConfig{HashBlock: pub.endpoint(), ReconnectInterval: 10 * time.Millisecond})
+	runSubscriber(t, s)
+
+	peer := pub.accept(t, ZMQTopicHashBlock)
+	receive(t, s.Resyncs)
+
+	hash := bytes.Repeat([]byte{0x66}, 32)
+	peer.publish(t, ZMQTopicHashBlock, hash, 0)
+	receive(t, s.HashBlocks)
+
+	// The publisher goes away; blocks announced meanwhile show up as a gap after redialing
+	peer.conn.Close()
+	peer = pub.accept(t, ZMQTopicHashBlock)
+	peer.publish(t, ZMQTopicHashBlock, hash, 3)
+	if n := receive(t, s.HashBlocks); n.Sequence != 3 {
+		t.Fatalf("unexpected hashblock after reconnect %+v", n)
+	}
+
+	gaps := receiveGaps(t, s.Resyncs, 1)
+	if want := (ZMQGap{Topic: ZMQTopicHashBlock, Expected: 1, Received: 3}); len(gaps) != 1 || gaps[0] != want {
+		t.Fatalf("got gaps %+v, want %+v", gaps, want)
+	}
+}